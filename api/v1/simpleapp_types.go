@@ -17,19 +17,122 @@ limitations under the License.
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DefaultServicePort is the port the Service exposes when spec.servicePort is omitted.
+const DefaultServicePort int32 = 80
+
+// LogLevelAnnotation adjusts the controller's log verbosity for a single SimpleApp.
+// Accepted values are error, info, debug, trace or a numeric logr V-level.
+const LogLevelAnnotation = "apps.myapp.io/log-level"
+
+// ReplicasOverrideAnnotation takes precedence over spec.replicas, so a single manifest can run
+// at a different scale per environment. It must hold a non-negative integer; invalid values are ignored.
+const ReplicasOverrideAnnotation = "simpleapp.myapp.io/replicas-override"
+
+// DeprecatedReplicasOverrideAnnotation is the former key of ReplicasOverrideAnnotation, still
+// honoured when the new one is absent.
+const DeprecatedReplicasOverrideAnnotation = "apps.myapp.io/replicas-override"
+
+// PreDeleteFinalizer holds back the deletion of a SimpleApp with a PreDeleteJob until the Job succeeded.
+const PreDeleteFinalizer = "apps.myapp.io/pre-delete"
+
+// NotificationURLAnnotation overrides the operator-wide webhook URL that rollout
+// notifications for this SimpleApp are posted to.
+const NotificationURLAnnotation = "apps.myapp.io/notification-url"
+
+// NotificationEventsAnnotation overrides which rollout notifications are sent for this SimpleApp,
+// as a comma-separated list of Notification* values. "none" disables them.
+const NotificationEventsAnnotation = "apps.myapp.io/notification-events"
+
+// RolloutTriggerAnnotation is the pod template annotation carrying spec.rolloutTrigger.
+const RolloutTriggerAnnotation = "apps.myapp.io/rollout-trigger"
+
+const (
+	// NotificationReady is sent when the SimpleApp becomes Ready.
+	NotificationReady = "Ready"
+	// NotificationDegraded is sent when a Ready SimpleApp loses ready replicas.
+	NotificationDegraded = "Degraded"
+)
+
+// ImagePolicyAnnotation is set on a Namespace to opt the SimpleApps in it into image
+// reference policies, as a comma-separated list of ImagePolicy* values.
+const ImagePolicyAnnotation = "apps.myapp.io/image-policy"
+
+const (
+	// ImagePolicyDigestRequired rejects images that are not pinned by digest.
+	ImagePolicyDigestRequired = "digest-required"
+	// ImagePolicyImmutableTag rejects updates that only change the tag of an image pinned by digest,
+	// since the tag is ignored and would misreport what is actually running.
+	ImagePolicyImmutableTag = "immutable-tag"
+)
+
+// PolicyConfigMapName is the name of the optional ConfigMap holding the SimpleApp policies of a namespace.
+const PolicyConfigMapName = "simpleapp-policy"
+
+// PolicyImagePolicyKey is the PolicyConfigMapName key listing image policies, in the same format
+// as ImagePolicyAnnotation. Both sources are combined.
+const PolicyImagePolicyKey = "imagePolicy"
+
 // SimpleAppSpec defines the desired state of SimpleApp
+// +kubebuilder:validation:XValidation:rule="!has(self.exposeService) || self.exposeService || !has(self.metrics)",message="metrics are scraped through the Service, so they require exposeService"
+// +kubebuilder:validation:XValidation:rule="!has(self.sidecars) || self.sidecars.all(c, c.name != (has(self.containerName) ? self.containerName : 'app'))",message="sidecar names must differ from containerName"
+// +kubebuilder:validation:XValidation:rule="!has(self.headless) || !self.headless || !has(self.serviceType) || self.serviceType == 'ClusterIP'",message="a headless Service must use the ClusterIP service type"
+// +kubebuilder:validation:XValidation:rule="!has(self.externalTrafficPolicy) || (has(self.serviceType) && self.serviceType in ['NodePort', 'LoadBalancer'])",message="externalTrafficPolicy requires the NodePort or LoadBalancer service type"
+// +kubebuilder:validation:XValidation:rule="!has(self.runOnce) || !self.runOnce || !(has(self.autoscaling) || has(self.podDisruptionBudget) || has(self.metrics) || has(self.sidecars) || (has(self.replicasFromNodeCount) && self.replicasFromNodeCount))",message="runOnce cannot be combined with autoscaling, podDisruptionBudget, metrics, sidecars or replicasFromNodeCount"
+// +kubebuilder:validation:XValidation:rule="!has(self.sessionAffinityTimeoutSeconds) || (has(self.sessionAffinity) && self.sessionAffinity == 'ClientIP')",message="sessionAffinityTimeoutSeconds requires the ClientIP session affinity"
+// +kubebuilder:validation:XValidation:rule="!has(self.targetPortName) || !has(self.metrics) || self.metrics.port == self.containerPort || self.targetPortName != (has(self.metrics.portName) ? self.metrics.portName : 'metrics')",message="targetPortName must differ from metrics.portName"
+// +kubebuilder:validation:XValidation:rule="!has(self.canary) || !(has(self.autoscaling) || (has(self.replicasFromNodeCount) && self.replicasFromNodeCount) || (has(self.runOnce) && self.runOnce) || has(self.storage))",message="canary cannot be combined with autoscaling, replicasFromNodeCount, runOnce or storage"
+// +kubebuilder:validation:XValidation:rule="!has(self.allowManualScaling) || !self.allowManualScaling || !((has(self.replicasFromNodeCount) && self.replicasFromNodeCount) || has(self.canary))",message="allowManualScaling cannot be combined with replicasFromNodeCount or canary"
+// +kubebuilder:validation:XValidation:rule="!has(self.preStop) || !has(self.lifecycle) || !has(self.lifecycle.preStop)",message="preStop and lifecycle.preStop are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="!has(self.adminPort) || (self.adminPort != self.containerPort && (!has(self.metrics) || self.metrics.port != self.adminPort))",message="adminPort must differ from containerPort and metrics.port"
+// +kubebuilder:validation:XValidation:rule="!has(self.adminPort) || ((!has(self.targetPortName) || self.targetPortName != 'admin') && (!has(self.metrics) || (has(self.metrics.portName) ? self.metrics.portName : 'metrics') != 'admin'))",message="the port name admin is reserved for adminPort"
+// +kubebuilder:validation:XValidation:rule="!has(self.storage) || !has(self.volumes) || self.volumes.all(v, v.name != 'storage')",message="the volume name storage is reserved for spec.storage"
+// +kubebuilder:validation:XValidation:rule="!has(self.nodeArch) || !has(self.nodeSelector) || !('kubernetes.io/arch' in self.nodeSelector) || self.nodeSelector['kubernetes.io/arch'] == self.nodeArch",message="nodeSelector's kubernetes.io/arch contradicts nodeArch"
+// +kubebuilder:validation:XValidation:rule="!has(self.dnsPolicy) || self.dnsPolicy != 'None' || has(self.dnsConfig)",message="the None dnsPolicy requires dnsConfig"
 type SimpleAppSpec struct {
 	// Image is the Docker image to run (e.g. nginx:latest, my-app:v1)
 	// +kubebuilder:validation:Required
 	Image string `json:"image"`
 
-	// Replicas defines how many instances of the application to run
-	// +kubebuilder:validation:Minimum=1
+	// ContainerName is the name of the application container in the pods
+	// +optional
+	// +kubebuilder:default=app
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	ContainerName string `json:"containerName,omitempty"`
+
+	// ImagePullPolicy of the application container. Use Always with mutable tags such as
+	// :latest so restarts pick up the newest image.
+	// +optional
+	// +kubebuilder:validation:Enum=Always;IfNotPresent;Never
+	// +kubebuilder:default=IfNotPresent
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// Replicas defines how many instances of the application to run. 0 scales the app down,
+	// e.g. outside office hours, while keeping its Service. Defaults to 1.
+	// The simpleapp.myapp.io/replicas-override annotation takes precedence.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
 	// +kubebuilder:default=1
-	Replicas int32 `json:"replicas,omitempty"`
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// ReplicasFromNodeCount runs one replica per schedulable node (ready, not cordoned, matching
+	// nodeSelector and with all taints tolerated) instead of spec.replicas, e.g. for per-node caches.
+	// The count is refreshed every minute. Cannot be combined with autoscaling.
+	// +optional
+	ReplicasFromNodeCount bool `json:"replicasFromNodeCount,omitempty"`
+
+	// AllowManualScaling leaves the replica count of an existing Deployment alone, so scaling it
+	// with kubectl scale sticks. Replicas (and the replicas-override annotation) then only apply
+	// when the Deployment is created.
+	// +optional
+	AllowManualScaling bool `json:"allowManualScaling,omitempty"`
 
 	// ContainerPort is the port the application listens on inside the container
 	// +kubebuilder:validation:Required
@@ -37,25 +140,661 @@ type SimpleAppSpec struct {
 	// +kubebuilder:validation:Maximum=65535
 	ContainerPort int32 `json:"containerPort"`
 
-	// ServicePort is the port exposed by the Kubernetes Service to the cluster
-	// +kubebuilder:default=80
+	// ServicePort is the port exposed by the Kubernetes Service to the cluster. It defaults to
+	// DefaultServicePort, or to ContainerPort when the operator runs with
+	// --service-port-follows-container-port. The defaulting webhook applies it rather than the CRD,
+	// whose defaults are applied first and would hide an omitted port from the webhook.
+	// +optional
 	ServicePort int32 `json:"servicePort,omitempty"`
+
+	// AdminPort, when set, exposes a second container port for admin and health endpoints, named
+	// admin on the container and on the Service, where it keeps its number
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	AdminPort *int32 `json:"adminPort,omitempty"`
+
+	// TargetPortName names the container port and makes the Service target it by name instead of
+	// by number, so the Service keeps routing to the pods while containerPort is being renumbered.
+	// +optional
+	// +kubebuilder:validation:MaxLength=15
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	TargetPortName string `json:"targetPortName,omitempty"`
+
+	// ExposeService controls whether a Service (and Ingress) is created for the app.
+	// Set it to false for workers without inbound traffic; an existing Service is then removed.
+	// Defaults to true.
+	// +optional
+	ExposeService *bool `json:"exposeService,omitempty"`
+
+	// ServiceType is the type of the generated Service. Defaults to ClusterIP.
+	// +optional
+	// +kubebuilder:validation:Enum=ClusterIP;NodePort;LoadBalancer
+	ServiceType corev1.ServiceType `json:"serviceType,omitempty"`
+
+	// ExternalTrafficPolicy of a NodePort or LoadBalancer Service. Local preserves the client source IP
+	// by only routing to pods on the receiving node. Defaults to Cluster.
+	// +optional
+	// +kubebuilder:validation:Enum=Cluster;Local
+	ExternalTrafficPolicy corev1.ServiceExternalTrafficPolicy `json:"externalTrafficPolicy,omitempty"`
+
+	// Headless creates the Service without a cluster IP (clusterIP: None) so its DNS name resolves
+	// to the individual pods, and gives each pod a DNS name under it for peer discovery.
+	// Requires the ClusterIP service type.
+	// +optional
+	Headless bool `json:"headless,omitempty"`
+
+	// PublishNotReadyAddresses makes the DNS records of a headless Service include pods that aren't
+	// ready yet, so peers can discover each other while starting up. Requires headless; defaults to false.
+	// +optional
+	PublishNotReadyAddresses *bool `json:"publishNotReadyAddresses,omitempty"`
+
+	// ExternalService names an existing Service, managed outside the operator (e.g. by a service mesh),
+	// that exposes the app instead of the generated one. The operator never modifies it and only warns
+	// when it doesn't select the app's pods; a previously generated Service is removed.
+	// +optional
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z]([-a-z0-9]*[a-z0-9])?$`
+	ExternalService string `json:"externalService,omitempty"`
+
+	// SessionAffinity set to ClientIP sends all connections of a client to the same pod (sticky sessions).
+	// Defaults to None.
+	// +optional
+	// +kubebuilder:validation:Enum=None;ClientIP
+	SessionAffinity corev1.ServiceAffinity `json:"sessionAffinity,omitempty"`
+
+	// SessionAffinityTimeoutSeconds is how long a client sticks to its pod with ClientIP session
+	// affinity. Defaults to 10800 (3 hours).
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=86400
+	SessionAffinityTimeoutSeconds *int32 `json:"sessionAffinityTimeoutSeconds,omitempty"`
+
+	// DualStack requests both IPv4 and IPv6 cluster IPs for the Service (ipFamilyPolicy
+	// PreferDualStack). On single-stack clusters the Service keeps one family and a warning is emitted.
+	// +optional
+	DualStack bool `json:"dualStack,omitempty"`
+
+	// ServiceAccountName is the ServiceAccount the pods run as.
+	// When empty, the namespace default ServiceAccount is used.
+	// +optional
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// AutomountServiceAccountToken controls whether the ServiceAccount token is mounted into the pods.
+	// Set it to false for apps that don't talk to the Kubernetes API. Defaults to the ServiceAccount's setting.
+	// +optional
+	AutomountServiceAccountToken *bool `json:"automountServiceAccountToken,omitempty"`
+
+	// NameTemplate is a Go template used to compute the name of the generated
+	// Deployment and Service, e.g. "{{ .Labels.env }}-{{ .Name }}".
+	// The template can reference .Name, .Namespace and .Labels of the SimpleApp
+	// and must render to a valid DNS-1123 label. Defaults to the SimpleApp name.
+	// +optional
+	NameTemplate string `json:"nameTemplate,omitempty"`
+
+	// SelectorLabels replaces the app label in the selectors of the Deployment, Service and other
+	// children, e.g. to fit an existing label scheme. The pods get them in addition to the app label;
+	// a selector label named app wins over it. Selectors are immutable, so they are set at creation.
+	// +optional
+	SelectorLabels map[string]string `json:"selectorLabels,omitempty"`
+
+	// DeploymentLabels are added to the metadata of the Deployment only, not to its pods or
+	// selector, e.g. for policies that match Deployments. The operator's own labels win over them.
+	// +optional
+	DeploymentLabels map[string]string `json:"deploymentLabels,omitempty"`
+
+	// Volumes lists ConfigMaps, scratch space and existing PersistentVolumeClaims to mount into the
+	// application container
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Volumes []VolumeSpec `json:"volumes,omitempty"`
+
+	// EnvFromSecret lists Secrets whose keys are exposed as environment variables
+	// in the application container. Missing Secrets don't block the Deployment.
+	// +optional
+	EnvFromSecret []string `json:"envFromSecret,omitempty"`
+
+	// LogLevel, when set, is passed to the application container as the LOG_LEVEL environment
+	// variable, e.g. "debug". It takes precedence over a LOG_LEVEL key of the envFromSecret Secrets.
+	// Unrelated to the log-level annotation, which sets the operator's own verbosity.
+	// +kubebuilder:validation:MaxLength=63
+	// +optional
+	LogLevel string `json:"logLevel,omitempty"`
+
+	// InitContainers run to completion, in order, before the application container starts,
+	// e.g. to run database migrations or fetch configuration
+	// +optional
+	InitContainers []corev1.Container `json:"initContainers,omitempty"`
+
+	// Sidecars run next to the application container in every pod, e.g. a logging agent or a proxy.
+	// Their names must differ from ContainerName.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Sidecars []corev1.Container `json:"sidecars,omitempty"`
+
+	// TerminationGracePeriodSeconds is how long pods get to shut down after SIGTERM
+	// before they are killed. Defaults to 30 seconds.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+
+	// PreStop is run in the application container before it receives SIGTERM,
+	// e.g. to stop accepting new connections and drain in-flight requests.
+	// Deprecated: use lifecycle.preStop instead; the two are mutually exclusive.
+	// +optional
+	PreStop *LifecycleHook `json:"preStop,omitempty"`
+
+	// Lifecycle holds the hooks run in the application container after it starts and before it
+	// is stopped
+	// +optional
+	Lifecycle *LifecycleSpec `json:"lifecycle,omitempty"`
+
+	// StartupProbe gives slow-starting apps time to boot. Until it succeeds, liveness and
+	// readiness probes are not run, so the container isn't killed while still starting;
+	// size failureThreshold * periodSeconds to cover the worst-case startup time.
+	// +optional
+	StartupProbe *corev1.Probe `json:"startupProbe,omitempty"`
+
+	// WorkingDir of the application container, for images whose entrypoint expects a different
+	// directory than the image default
+	// +optional
+	WorkingDir string `json:"workingDir,omitempty"`
+
+	// Stdin keeps a stdin stream open for the application container, e.g. for kubectl attach
+	// +optional
+	Stdin bool `json:"stdin,omitempty"`
+
+	// TTY allocates a terminal for the application container. Usually combined with Stdin.
+	// +optional
+	TTY bool `json:"tty,omitempty"`
+
+	// PodSecurityContext holds pod-level security attributes (e.g. runAsNonRoot, fsGroup, seccompProfile).
+	// In namespaces enforcing the restricted Pod Security Standard, the defaulting webhook fills in a
+	// compliant context when it is omitted.
+	// +optional
+	PodSecurityContext *corev1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+
+	// SecurityContext holds the security attributes of the application container
+	// (e.g. allowPrivilegeEscalation, capabilities, readOnlyRootFilesystem).
+	// Defaulted like PodSecurityContext.
+	// +optional
+	SecurityContext *corev1.SecurityContext `json:"securityContext,omitempty"`
+
+	// GPUs is the number of nvidia.com/gpu devices the application container is limited to, and
+	// requests. Zero requests none.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	GPUs int32 `json:"gpus,omitempty"`
+
+	// NodeSelector restricts the pods to nodes carrying all of these labels
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// NodeArch pins the pods to nodes of one CPU architecture, e.g. when the image isn't multi-arch,
+	// by adding a kubernetes.io/arch entry to the node selector
+	// +kubebuilder:validation:Enum=amd64;arm64;ppc64le;s390x
+	// +optional
+	NodeArch string `json:"nodeArch,omitempty"`
+
+	// Tolerations let the pods schedule onto nodes with matching taints
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Affinity holds node, pod affinity and anti-affinity scheduling rules for the pods
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// TopologySpreadConstraints spread the pods across zones, nodes or other topology domains.
+	// A constraint without a labelSelector selects the pods of this SimpleApp.
+	// +optional
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// PriorityClassName is the PriorityClass of the pods, e.g. so latency-sensitive apps preempt
+	// batch workloads under resource pressure
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// HostAliases are added to the /etc/hosts file of the pods
+	// +optional
+	HostAliases []corev1.HostAlias `json:"hostAliases,omitempty"`
+
+	// DNSPolicy is the DNS policy of the pods, ClusterFirst unless set
+	// +kubebuilder:validation:Enum=ClusterFirst;ClusterFirstWithHostNet;Default;None
+	// +optional
+	DNSPolicy corev1.DNSPolicy `json:"dnsPolicy,omitempty"`
+
+	// DNSConfig holds nameservers, search domains and resolver options merged into the DNS
+	// configuration generated from DNSPolicy
+	// +optional
+	DNSConfig *corev1.PodDNSConfig `json:"dnsConfig,omitempty"`
+
+	// PodDisruptionBudget, when set, limits how many pods voluntary disruptions
+	// (e.g. node drains) may take down at once
+	// +optional
+	PodDisruptionBudget *PodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
+
+	// NetworkPolicy, when set, generates a NetworkPolicy that only lets traffic reach the pods
+	// on their container, metrics and admin ports, from the sources it lists
+	// +optional
+	NetworkPolicy *NetworkPolicySpec `json:"networkPolicy,omitempty"`
+
+	// Metrics exposes the app's Prometheus metrics port on the Service and, when the
+	// Prometheus Operator is installed, generates a ServiceMonitor scraping it
+	// +optional
+	Metrics *MetricsSpec `json:"metrics,omitempty"`
+
+	// PrometheusScrape, when set, stamps prometheus.io/scrape, port and path annotations on the pods
+	// for annotation-based Prometheus configurations, as a lighter alternative to Metrics
+	// +optional
+	PrometheusScrape *PrometheusScrapeSpec `json:"prometheusScrape,omitempty"`
+
+	// Autoscaling, when set, generates a HorizontalPodAutoscaler for the Deployment.
+	// Replicas then only sets the initial size; the autoscaler owns the replica count.
+	// +optional
+	Autoscaling *AutoscalingSpec `json:"autoscaling,omitempty"`
+
+	// ProgressDeadlineSeconds is how long a rollout may make no progress before it is reported
+	// as failed. Defaults to 600, like Deployments.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	ProgressDeadlineSeconds *int32 `json:"progressDeadlineSeconds,omitempty"`
+
+	// AutoRollback, when true, reverts a new image whose rollout exceeds the progress deadline:
+	// the Deployment goes back to status.lastGoodImage until spec.image changes again
+	// +optional
+	AutoRollback bool `json:"autoRollback,omitempty"`
+
+	// Suspend, when true, stops reconciling the SimpleApp: its children are left as they are,
+	// including manual changes, until it is set back to false
+	// +optional
+	Suspend *bool `json:"suspend,omitempty"`
+
+	// Paused pauses the Deployment: spec changes are still applied to it but not rolled out until
+	// paused is set back to false, e.g. to stage several changes during a migration. Unlike suspend,
+	// the operator keeps reconciling the SimpleApp.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+
+	// RunOnce runs the app to completion as a Job instead of a Deployment, e.g. for batch tasks.
+	// The Job gets the app's pod template without a Service; replicas are ignored. A Job can't be
+	// changed once created, so delete it to run it again with the current spec.
+	// +optional
+	RunOnce bool `json:"runOnce,omitempty"`
+
+	// RolloutTrigger is copied onto the pod template, so changing it to any new value (e.g. a
+	// timestamp) restarts the pods with the current spec, like kubectl rollout restart.
+	// Removing it restarts them too.
+	// +optional
+	// +kubebuilder:validation:MaxLength=253
+	RolloutTrigger string `json:"rolloutTrigger,omitempty"`
+
+	// Storage, when set, gives the app a PersistentVolumeClaim of its own, e.g. for a stateful
+	// single-replica app. The claim is kept when the field is removed, so no data is lost by accident,
+	// and deleted with the SimpleApp.
+	// +optional
+	Storage *StorageSpec `json:"storage,omitempty"`
+
+	// Canary, when set, runs part of the replicas with another image in a second Deployment behind the
+	// same Service, so that share of the traffic tries the new version. Remove it to end the canary.
+	// +optional
+	Canary *CanarySpec `json:"canary,omitempty"`
+
+	// PreDeleteJob, when set, is run as a Job when the SimpleApp is deleted, e.g. to deregister the
+	// app from an external system. Deletion waits for the Job to succeed.
+	// +optional
+	PreDeleteJob *PreDeleteJobSpec `json:"preDeleteJob,omitempty"`
+}
+
+// PreDeleteJobSpec describes the cleanup Job run before a SimpleApp is deleted
+type PreDeleteJobSpec struct {
+	// Image of the Job's container. Defaults to the application image.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Command run by the Job's container
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	Command []string `json:"command"`
+}
+
+// AutoscalingSpec configures the HorizontalPodAutoscaler generated for the app
+// +kubebuilder:validation:XValidation:rule="!has(self.minReplicas) || self.minReplicas <= self.maxReplicas",message="minReplicas must not exceed maxReplicas"
+type AutoscalingSpec struct {
+	// MinReplicas is the lower limit for the number of replicas. Defaults to 1.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas is the upper limit for the number of replicas
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// TargetCPUUtilizationPercentage is the average CPU utilization, relative to the
+	// container's CPU request, the autoscaler aims for
+	// +optional
+	// +kubebuilder:default=80
+	// +kubebuilder:validation:Minimum=1
+	TargetCPUUtilizationPercentage int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+
+	// ScaleTargetContainer is the container whose CPU usage drives scaling, so sidecars
+	// don't skew the average. Defaults to the application container.
+	// +optional
+	ScaleTargetContainer string `json:"scaleTargetContainer,omitempty"`
+}
+
+// PrometheusScrapeSpec configures the prometheus.io/* scrape annotations of the pods
+type PrometheusScrapeSpec struct {
+	// Port is the container port to scrape. Defaults to the Metrics port, or the ContainerPort.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port,omitempty"`
+
+	// Path is the HTTP path serving metrics
+	// +optional
+	// +kubebuilder:default="/metrics"
+	Path string `json:"path,omitempty"`
+}
+
+// MetricsSpec describes where the application serves Prometheus metrics
+type MetricsSpec struct {
+	// Port is the container port serving metrics. It may be the ContainerPort itself.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port"`
+
+	// PortName is the name of the metrics port on the container and the Service
+	// +optional
+	// +kubebuilder:default=metrics
+	// +kubebuilder:validation:MaxLength=15
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	PortName string `json:"portName,omitempty"`
+
+	// Path is the HTTP path metrics are served on
+	// +optional
+	// +kubebuilder:default="/metrics"
+	// +kubebuilder:validation:Pattern=`^/`
+	Path string `json:"path,omitempty"`
+
+	// Interval is how often Prometheus scrapes the app, e.g. 30s or 1m
+	// +optional
+	// +kubebuilder:default="30s"
+	// +kubebuilder:validation:Pattern=`^([0-9]+(ms|s|m|h))+$`
+	Interval string `json:"interval,omitempty"`
+}
+
+// PodDisruptionBudgetSpec configures the PodDisruptionBudget generated for the app
+// +kubebuilder:validation:XValidation:rule="has(self.minAvailable) != has(self.maxUnavailable)",message="exactly one of minAvailable or maxUnavailable must be set"
+type PodDisruptionBudgetSpec struct {
+	// MinAvailable is the number or percentage of pods that must stay available
+	// +optional
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+
+	// MaxUnavailable is the number or percentage of pods that may be unavailable at once
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// NetworkPolicySpec configures the NetworkPolicy generated for the app
+type NetworkPolicySpec struct {
+	// From lists the pods, namespaces and IP blocks allowed to connect. When empty, any source
+	// may connect, on the app's ports only.
+	// +optional
+	From []networkingv1.NetworkPolicyPeer `json:"from,omitempty"`
+}
+
+// BlocksDisruptions reports whether the budget leaves no pod that may be evicted when the
+// app runs the given number of replicas, which stalls node drains indefinitely.
+// Percentages are rounded up, as the disruption controller does.
+func (s *PodDisruptionBudgetSpec) BlocksDisruptions(replicas int32) bool {
+	switch {
+	case replicas == 0:
+		// Nothing to evict
+		return false
+	case s.MinAvailable != nil:
+		minAvailable, err := intstr.GetScaledValueFromIntOrPercent(s.MinAvailable, int(replicas), true)
+		return err == nil && minAvailable >= int(replicas)
+	case s.MaxUnavailable != nil:
+		maxUnavailable, err := intstr.GetScaledValueFromIntOrPercent(s.MaxUnavailable, int(replicas), true)
+		return err == nil && maxUnavailable <= 0
+	}
+	return false
+}
+
+// LifecycleSpec holds the lifecycle hooks of the application container
+type LifecycleSpec struct {
+	// PostStart is run right after the container is created, e.g. to warm up caches. The
+	// container isn't marked running until it completes, and is killed if it fails.
+	// +optional
+	PostStart *LifecycleHook `json:"postStart,omitempty"`
+
+	// PreStop is run before the container receives SIGTERM, e.g. to stop accepting new
+	// connections and drain in-flight requests
+	// +optional
+	PreStop *LifecycleHook `json:"preStop,omitempty"`
+}
+
+// LifecycleHook runs either a command or an HTTP GET request in the application container
+// +kubebuilder:validation:XValidation:rule="has(self.exec) != has(self.httpGet)",message="exactly one of exec or httpGet must be set"
+type LifecycleHook struct {
+	// Exec runs a command inside the container
+	// +optional
+	Exec *corev1.ExecAction `json:"exec,omitempty"`
+
+	// HTTPGet sends an HTTP GET request to the container
+	// +optional
+	HTTPGet *corev1.HTTPGetAction `json:"httpGet,omitempty"`
+}
+
+// CanarySpec describes the canary track of a SimpleApp
+type CanarySpec struct {
+	// Image is the container image the canary replicas run
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Image string `json:"image"`
+
+	// Weight is the percentage of the replicas that run the canary. It is rounded to whole replicas,
+	// keeping at least one canary and one stable replica; a single replica is never split.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=99
+	Weight int32 `json:"weight"`
+}
+
+// StorageSpec describes the PersistentVolumeClaim provisioned for a SimpleApp.
+type StorageSpec struct {
+	// Size is the requested capacity, e.g. 10Gi. It can grow if the storage class allows volume
+	// expansion, but never shrink.
+	// +kubebuilder:validation:Required
+	Size resource.Quantity `json:"size"`
+
+	// StorageClassName selects the storage class; the cluster default is used when unset.
+	// It cannot be changed once the claim exists.
+	// +optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// MountPath is the absolute path the claim is mounted at inside the application container
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^/`
+	MountPath string `json:"mountPath"`
+}
+
+// VolumeSpec mounts a ConfigMap, an emptyDir or a PersistentVolumeClaim into the application container
+// +kubebuilder:validation:XValidation:rule="[has(self.configMap), has(self.emptyDir), has(self.persistentVolumeClaim)].filter(x, x).size() == 1",message="exactly one of configMap, emptyDir or persistentVolumeClaim must be set"
+type VolumeSpec struct {
+	// Name identifies the volume inside the pod
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+
+	// ConfigMap is the name of the ConfigMap to mount read-only.
+	// The Deployment is created even if the ConfigMap does not exist yet; pods wait for it.
+	// +optional
+	ConfigMap string `json:"configMap,omitempty"`
+
+	// EmptyDir mounts scratch space that lives as long as the pod
+	// +optional
+	EmptyDir *corev1.EmptyDirVolumeSource `json:"emptyDir,omitempty"`
+
+	// PersistentVolumeClaim is the name of an existing claim in the SimpleApp's namespace to mount.
+	// The Deployment is created even if the claim does not exist yet; pods wait for it.
+	// +optional
+	PersistentVolumeClaim string `json:"persistentVolumeClaim,omitempty"`
+
+	// MountPath is the absolute path the volume is mounted at inside the container
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^/`
+	MountPath string `json:"mountPath"`
 }
 
 // SimpleAppStatus defines the observed state of SimpleApp
 type SimpleAppStatus struct {
+	// ObservedGeneration is the metadata.generation of the spec the status reflects; a lower value
+	// than metadata.generation means the controller hasn't acted on the latest change yet
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// LastReconcileTime is when a reconcile last changed the status. Reconciles that find nothing
+	// to update don't rewrite the status, so it can be older than the last reconcile.
+	// +optional
+	LastReconcileTime *metav1.Time `json:"lastReconcileTime,omitempty"`
+
 	// ReadyReplicas tells us how many pods are actually running
 	ReadyReplicas int32 `json:"readyReplicas"`
 
-	// ServiceStatus reports the general health
+	// UnavailableReplicas is the number of desired pods that are not available yet
+	// +optional
+	UnavailableReplicas int32 `json:"unavailableReplicas,omitempty"`
+
+	// UpdatedReplicas is the number of pods already running the latest pod template
+	// +optional
+	UpdatedReplicas int32 `json:"updatedReplicas,omitempty"`
+
+	// TotalRestarts is the number of container restarts summed over the app's current pods; a
+	// growing count points at a crash loop
+	// +optional
+	TotalRestarts int32 `json:"totalRestarts,omitempty"`
+
+	// Message explains why pods aren't coming up (e.g. "1 pod(s) ImagePullBackOff"); empty while healthy
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// ServiceStatus reports the general health: ServiceStatusFailed while the rollout is stuck
+	// past its progress deadline, empty otherwise
+	// +optional
 	ServiceStatus string `json:"serviceStatus,omitempty"`
+
+	// RunningImage is the image currently set on the app container of the Deployment's pod template,
+	// e.g. a digest reference such as "nginx@sha256:..."
+	// +optional
+	RunningImage string `json:"runningImage,omitempty"`
+
+	// LastGoodImage is the last image whose rollout completed, which spec.autoRollback goes back to
+	// +optional
+	LastGoodImage string `json:"lastGoodImage,omitempty"`
+
+	// FailedImage is the spec.image that spec.autoRollback reverted after its rollout got stuck;
+	// cleared once spec.image changes
+	// +optional
+	FailedImage string `json:"failedImage,omitempty"`
+
+	// ServiceDNS is the in-cluster address of the generated Service
+	// (<service>.<namespace>.svc.cluster.local:<port>). For spec.externalService it has no port,
+	// and is the external name of an ExternalName Service
+	// +optional
+	ServiceDNS string `json:"serviceDNS,omitempty"`
+
+	// ServiceEndpoint is where the Service in front of the app can be reached; unset without a Service
+	// +optional
+	ServiceEndpoint *ServiceEndpoint `json:"serviceEndpoint,omitempty"`
+
+	// Canary reports the canary track while spec.canary is set
+	// +optional
+	Canary *CanaryStatus `json:"canary,omitempty"`
+
+	// Summary is a one-line overview of the app, e.g. "3/3 ready, ClusterIP 10.0.0.5:80, image nginx:1.25"
+	// +optional
+	Summary string `json:"summary,omitempty"`
+
+	// Conditions represent the latest available observations of the SimpleApp's state
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
+// ServiceEndpoint describes the addresses of the Service in front of a SimpleApp.
+type ServiceEndpoint struct {
+	// ClusterIP is the in-cluster IP of the Service, "None" for a headless Service
+	// +optional
+	ClusterIP string `json:"clusterIP,omitempty"`
+
+	// Port is the port the Service exposes the app on
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// ExternalAddress is the IP or hostname of a LoadBalancer Service once the load balancer is provisioned
+	// +optional
+	ExternalAddress string `json:"externalAddress,omitempty"`
+}
+
+// CanaryStatus describes the canary Deployment of a SimpleApp
+type CanaryStatus struct {
+	// Image is the image of the canary pods
+	Image string `json:"image"`
+
+	// Replicas is the number of replicas running the canary
+	Replicas int32 `json:"replicas"`
+
+	// ReadyReplicas is the number of canary pods that are ready
+	ReadyReplicas int32 `json:"readyReplicas"`
+
+	// Message explains why canary pods are not coming up, e.g. "1 pod(s) CrashLoopBackOff"
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ConditionReady is True when all desired replicas of the SimpleApp are ready.
+const ConditionReady = "Ready"
+
+// ServiceStatusFailed is the SimpleAppStatus.ServiceStatus of an app whose rollout exceeded
+// its progress deadline.
+const ServiceStatusFailed = "Failed"
+
+// ConditionSelectorCollision is True when the app selector also matches pods
+// that are not managed by the SimpleApp, which would let the Service capture their traffic.
+const ConditionSelectorCollision = "SelectorCollision"
+
+// ConditionPaused is True while spec.paused holds back the rollout of the Deployment.
+const ConditionPaused = "Paused"
+
+// ConditionReferencesMissing is True while ConfigMaps, PersistentVolumeClaims or Secrets referenced
+// by the spec don't exist; its message lists them.
+const ConditionReferencesMissing = "ReferencesMissing"
+
+// ConditionComplete is True once the Job of a SimpleApp with spec.runOnce succeeded, and False
+// while it runs or after it failed.
+const ConditionComplete = "Complete"
+
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 //+kubebuilder:printcolumn:name="Image",type="string",JSONPath=".spec.image"
 //+kubebuilder:printcolumn:name="Replicas",type="integer",JSONPath=".spec.replicas"
 //+kubebuilder:printcolumn:name="Ready",type="integer",JSONPath=".status.readyReplicas"
+//+kubebuilder:printcolumn:name="Unavailable",type="integer",JSONPath=".status.unavailableReplicas"
+//+kubebuilder:printcolumn:name="Restarts",type="integer",JSONPath=".status.totalRestarts"
+//+kubebuilder:printcolumn:name="Summary",type="string",JSONPath=".status.summary"
+//+kubebuilder:printcolumn:name="Cluster-IP",type="string",JSONPath=".status.serviceEndpoint.clusterIP",priority=1
+//+kubebuilder:printcolumn:name="External-Address",type="string",JSONPath=".status.serviceEndpoint.externalAddress",priority=1
 //+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // SimpleApp is the Schema for the simpleapps API
@@ -20,11 +20,14 @@ import (
 	"crypto/tls"
 	"flag"
 	"os"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -37,6 +40,9 @@ import (
 
 	appsv1 "github.com/gxanlvxgx/simple-app-operator/api/v1"
 	"github.com/gxanlvxgx/simple-app-operator/internal/controller"
+	"github.com/gxanlvxgx/simple-app-operator/internal/health"
+	"github.com/gxanlvxgx/simple-app-operator/internal/policy"
+	webhookv1 "github.com/gxanlvxgx/simple-app-operator/internal/webhook/v1"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -61,6 +67,16 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var resyncPeriod time.Duration
+	var notificationURL, notificationEvents string
+	var notificationMinInterval time.Duration
+	var commonLabels string
+	var maxConcurrentReconciles int
+	var manageServices bool
+	var gracefulShutdownTimeout time.Duration
+	var baseBackoff, maxBackoff time.Duration
+	var resyncJitter float64
+	var servicePortFollowsContainerPort bool
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -79,6 +95,37 @@ func main() {
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.DurationVar(&resyncPeriod, "resync-period", 0,
+		"If non-zero, every SimpleApp is reconciled again this long after a successful reconcile, "+
+			"correcting drift without waiting for a watch event. Leave as 0 to disable.")
+	flag.StringVar(&notificationURL, "notification-webhook-url", "",
+		"If set, Ready/Degraded rollout notifications are posted to this URL as Slack-compatible JSON. "+
+			"SimpleApps can override it with the apps.myapp.io/notification-url annotation.")
+	flag.StringVar(&notificationEvents, "notification-events", "Ready,Degraded",
+		"Comma-separated rollout notifications to send. "+
+			"SimpleApps can override it with the apps.myapp.io/notification-events annotation.")
+	flag.DurationVar(&notificationMinInterval, "notification-min-interval", 5*time.Minute,
+		"The minimum time between two rollout notifications for the same SimpleApp.")
+	flag.StringVar(&commonLabels, "common-labels", "",
+		"Comma-separated key=value labels added to every Deployment and Service managed by the controller, "+
+			"e.g. team=platform,cost-center=42.")
+	flag.IntVar(&maxConcurrentReconciles, "max-concurrent-reconciles", 1,
+		"The number of SimpleApps reconciled in parallel. Raise it on clusters with many SimpleApps.")
+	flag.BoolVar(&manageServices, "manage-services", true,
+		"If false, the controller never creates, updates or deletes Services, e.g. while a separate networking "+
+			"controller takes them over. Existing Services are left as they are.")
+	flag.DurationVar(&gracefulShutdownTimeout, "graceful-shutdown-timeout", 30*time.Second,
+		"How long the manager waits for in-flight reconciles to finish after SIGTERM before exiting. "+
+			"Keep it below the pod's terminationGracePeriodSeconds.")
+	flag.DurationVar(&baseBackoff, "reconcile-base-backoff", 5*time.Millisecond,
+		"The delay before retrying a failed reconcile of a SimpleApp; it doubles with each consecutive failure.")
+	flag.DurationVar(&maxBackoff, "reconcile-max-backoff", 1000*time.Second,
+		"The longest delay between retries of a SimpleApp whose reconciles keep failing.")
+	flag.Float64Var(&resyncJitter, "resync-jitter", 0.1,
+		"Each resync is delayed by up to this fraction of -resync-period, so SimpleApps reconciled together "+
+			"don't requeue together and load the API server all at once.")
+	flag.BoolVar(&servicePortFollowsContainerPort, "service-port-follows-container-port", false,
+		"If set, an omitted spec.servicePort defaults to spec.containerPort instead of 80.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -87,6 +134,25 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	childLabels, err := labels.ConvertSelectorToLabelsMap(commonLabels)
+	if err != nil {
+		setupLog.Error(err, "invalid -common-labels")
+		os.Exit(1)
+	}
+	if maxConcurrentReconciles < 1 {
+		setupLog.Error(nil, "-max-concurrent-reconciles must be at least 1", "value", maxConcurrentReconciles)
+		os.Exit(1)
+	}
+	if baseBackoff <= 0 || maxBackoff < baseBackoff {
+		setupLog.Error(nil, "-reconcile-base-backoff must be positive and at most -reconcile-max-backoff",
+			"base", baseBackoff, "max", maxBackoff)
+		os.Exit(1)
+	}
+	if resyncJitter < 0 {
+		setupLog.Error(nil, "-resync-jitter must not be negative", "value", resyncJitter)
+		os.Exit(1)
+	}
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -161,6 +227,8 @@ func main() {
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "604f452b.myapp.io",
+		// Give in-flight reconciles time to finish their writes when the pod is stopped
+		GracefulShutdownTimeout: &gracefulShutdownTimeout,
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
@@ -178,13 +246,42 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Namespace policy ConfigMaps are cached and invalidated through the manager's ConfigMap informer
+	policies := &policy.Loader{Reader: mgr.GetAPIReader()}
+	if err := policies.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to set up the namespace policy cache")
+		os.Exit(1)
+	}
+
 	if err := (&controller.SimpleAppReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:                          mgr.GetClient(),
+		Scheme:                          mgr.GetScheme(),
+		Recorder:                        mgr.GetEventRecorderFor("simpleapp-controller"),
+		ResyncPeriod:                    resyncPeriod,
+		APIReader:                       mgr.GetAPIReader(),
+		CommonLabels:                    childLabels,
+		MaxConcurrentReconciles:         maxConcurrentReconciles,
+		DisableServiceManagement:        !manageServices,
+		BaseBackoff:                     baseBackoff,
+		MaxBackoff:                      maxBackoff,
+		ResyncJitter:                    resyncJitter,
+		ServicePortFollowsContainerPort: servicePortFollowsContainerPort,
+		Notifier: &controller.Notifier{
+			URL:         notificationURL,
+			Events:      strings.Split(notificationEvents, ","),
+			MinInterval: notificationMinInterval,
+		},
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "SimpleApp")
 		os.Exit(1)
 	}
+	// nolint:goconst
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err := webhookv1.SetupSimpleAppWebhookWithManager(mgr, policies, servicePortFollowsContainerPort); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "SimpleApp")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -195,6 +292,10 @@ func main() {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
+	if err := mgr.AddReadyzCheck("leader", health.LeaderCheck(mgr.Elected())); err != nil {
+		setupLog.Error(err, "unable to set up leader ready check")
+		os.Exit(1)
+	}
 
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
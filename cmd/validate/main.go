@@ -0,0 +1,182 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command validate lints SimpleApp manifests without a cluster, running the checks of the
+// admission webhook on them. Other kinds in the manifests are skipped. It exits with 1 when a
+// SimpleApp is invalid and with 2 when a file can't be read or parsed.
+//
+//	validate [-quiet] [-service-port-follows-container-port] FILE...
+//
+// A FILE of "-" reads standard input.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+
+	appsv1 "github.com/gxanlvxgx/simple-app-operator/api/v1"
+	webhookv1 "github.com/gxanlvxgx/simple-app-operator/internal/webhook/v1"
+)
+
+const (
+	exitInvalid = 1
+	exitError   = 2
+)
+
+func main() {
+	// The webhook logs every validation, which is noise on the command line
+	logf.SetLogger(logr.Discard())
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run validates the manifests named by args and returns the exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	quiet := flags.Bool("quiet", false, "Don't print warnings or the names of valid SimpleApps.")
+	followsContainerPort := flags.Bool("service-port-follows-container-port", false,
+		"Default an omitted servicePort to containerPort, like the manager flag of the same name.")
+	flags.Usage = func() {
+		_, _ = fmt.Fprintln(stderr, "Usage: validate [-quiet] [-service-port-follows-container-port] FILE...")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return exitError
+	}
+
+	code := 0
+	for _, path := range flags.Args() {
+		code = max(code, validateFile(path, stdin, stdout, stderr, *quiet, *followsContainerPort))
+	}
+	return code
+}
+
+// validateFile validates the manifests in path, printing the results, and returns the exit code.
+func validateFile(path string, stdin io.Reader, stdout, stderr io.Writer, quiet, followsContainerPort bool) int {
+	r := stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			_, _ = fmt.Fprintln(stderr, err)
+			return exitError
+		}
+		defer func() { _ = f.Close() }()
+		r = f
+	}
+
+	code := 0
+	results, err := validateManifests(r, followsContainerPort)
+	for _, res := range results {
+		if !quiet {
+			for _, warning := range res.warnings {
+				_, _ = fmt.Fprintf(stdout, "%s: %s: warning: %s\n", path, res.name, warning)
+			}
+		}
+		switch {
+		case res.err != nil:
+			_, _ = fmt.Fprintf(stdout, "%s: %s: %v\n", path, res.name, res.err)
+			code = exitInvalid
+		case !quiet:
+			_, _ = fmt.Fprintf(stdout, "%s: %s: valid\n", path, res.name)
+		}
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "%s: %v\n", path, err)
+		code = exitError
+	}
+	return code
+}
+
+// result is the outcome of validating one SimpleApp.
+type result struct {
+	// name identifies the SimpleApp, by namespace/name when it has a namespace
+	name     string
+	warnings []string
+	err      error
+}
+
+// validateManifests validates the SimpleApps in a stream of YAML or JSON documents. It stops at the
+// first document that can't be parsed, returning the results so far and the error.
+func validateManifests(r io.Reader, followsContainerPort bool) ([]result, error) {
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(r))
+	var results []result
+	for doc := 1; ; doc++ {
+		data, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return results, nil
+		}
+		if err != nil {
+			return results, fmt.Errorf("reading document %d: %w", doc, err)
+		}
+
+		var typeMeta struct {
+			APIVersion string `json:"apiVersion"`
+			Kind       string `json:"kind"`
+		}
+		if err := yaml.Unmarshal(data, &typeMeta); err != nil {
+			return results, fmt.Errorf("parsing document %d: %w", doc, err)
+		}
+		// Empty documents, e.g. after a trailing separator, and other kinds are skipped
+		gv, err := schema.ParseGroupVersion(typeMeta.APIVersion)
+		if err != nil || gv.Group != appsv1.GroupVersion.Group || typeMeta.Kind != "SimpleApp" {
+			continue
+		}
+		if gv != appsv1.GroupVersion {
+			return results, fmt.Errorf("document %d: unsupported apiVersion %s, expected %s",
+				doc, typeMeta.APIVersion, appsv1.GroupVersion)
+		}
+
+		var simpleapp appsv1.SimpleApp
+		// Unknown fields would be pruned by the API server, so they are most likely typos
+		if err := yaml.UnmarshalStrict(data, &simpleapp); err != nil {
+			return results, fmt.Errorf("parsing document %d: %w", doc, err)
+		}
+		results = append(results, validate(&simpleapp, followsContainerPort))
+	}
+}
+
+// validate defaults a SimpleApp like the mutating webhook, without looking up its Namespace, and
+// validates it like the validating webhook, without the namespace image policies.
+// followsContainerPort is the -service-port-follows-container-port flag of the manager.
+func validate(simpleapp *appsv1.SimpleApp, followsContainerPort bool) result {
+	res := result{name: simpleapp.Name}
+	if simpleapp.Namespace != "" {
+		res.name = simpleapp.Namespace + "/" + simpleapp.Name
+	}
+	defaulter := &webhookv1.SimpleAppCustomDefaulter{ServicePortFollowsContainerPort: followsContainerPort}
+	if err := defaulter.Default(context.Background(), simpleapp); err != nil {
+		res.err = err
+		return res
+	}
+	validator := &webhookv1.SimpleAppCustomValidator{ServicePortFollowsContainerPort: followsContainerPort}
+	res.warnings, res.err = validator.Validate(simpleapp)
+	return res
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const validManifest = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+---
+apiVersion: apps.myapp.io/v1
+kind: SimpleApp
+metadata:
+  name: web
+  namespace: default
+spec:
+  image: nginx:1.27
+  containerPort: 80
+`
+
+var _ = Describe("validate", func() {
+	var stdout, stderr *bytes.Buffer
+
+	BeforeEach(func() {
+		stdout, stderr = &bytes.Buffer{}, &bytes.Buffer{}
+	})
+
+	writeManifest := func(content string) string {
+		path := filepath.Join(GinkgoT().TempDir(), "app.yaml")
+		Expect(os.WriteFile(path, []byte(content), 0o600)).To(Succeed())
+		return path
+	}
+
+	It("accepts a valid SimpleApp and skips other kinds", func() {
+		path := writeManifest(validManifest)
+		Expect(run([]string{path}, nil, stdout, stderr)).To(Equal(0))
+		Expect(stdout.String()).To(Equal(path + ": default/web: valid\n"))
+		Expect(stderr.String()).To(BeEmpty())
+	})
+
+	It("reads standard input", func() {
+		Expect(run([]string{"-quiet", "-"}, strings.NewReader(validManifest), stdout, stderr)).To(Equal(0))
+		Expect(stdout.String()).To(BeEmpty())
+	})
+
+	It("rejects what the webhook rejects, naming the field", func() {
+		path := writeManifest(strings.Replace(validManifest, "nginx:1.27", "nginx:bad tag", 1))
+		Expect(run([]string{path}, nil, stdout, stderr)).To(Equal(exitInvalid))
+		Expect(stdout.String()).To(ContainSubstring("default/web"))
+		Expect(stdout.String()).To(ContainSubstring("spec.image"))
+	})
+
+	It("prints the warnings of the webhook", func() {
+		path := writeManifest(validManifest + "  podDisruptionBudget:\n    minAvailable: 1\n")
+		Expect(run([]string{path}, nil, stdout, stderr)).To(Equal(0))
+		Expect(stdout.String()).To(ContainSubstring("default/web: warning: "))
+	})
+
+	It("defaults servicePort like the manager flag", func() {
+		path := writeManifest(strings.Replace(validManifest, "containerPort: 80", "containerPort: 8080", 1) +
+			"  headless: true\n")
+		Expect(run([]string{path}, nil, stdout, stderr)).To(Equal(0))
+		Expect(stdout.String()).To(ContainSubstring("default/web: warning: "))
+
+		stdout.Reset()
+		Expect(run([]string{"-service-port-follows-container-port", path}, nil, stdout, stderr)).To(Equal(0))
+		Expect(stdout.String()).To(Equal(path + ": default/web: valid\n"))
+	})
+
+	It("rejects unknown fields", func() {
+		path := writeManifest(validManifest + "  replcias: 3\n")
+		Expect(run([]string{path}, nil, stdout, stderr)).To(Equal(exitError))
+		Expect(stderr.String()).To(ContainSubstring("replcias"))
+	})
+
+	It("reports files that don't exist", func() {
+		valid := writeManifest(validManifest)
+		Expect(run([]string{valid, filepath.Join(GinkgoT().TempDir(), "missing.yaml")}, nil, stdout, stderr)).
+			To(Equal(exitError))
+		Expect(stdout.String()).To(ContainSubstring("default/web: valid"))
+		Expect(stderr.String()).To(ContainSubstring("missing.yaml"))
+	})
+
+	It("requires a file", func() {
+		Expect(run(nil, nil, stdout, stderr)).To(Equal(exitError))
+		Expect(stderr.String()).To(ContainSubstring("Usage"))
+	})
+})
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// authConfig holds the credentials that protect the handlers changing the cluster.
+// A zero authConfig leaves them open.
+type authConfig struct {
+	user, password string
+	token          string
+}
+
+// enabled reports whether any credentials are configured
+func (c authConfig) enabled() bool {
+	return c.user != "" || c.token != ""
+}
+
+// authorized checks a request's basic auth credentials or bearer token
+func (c authConfig) authorized(r *http.Request) bool {
+	if c.token != "" {
+		if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && equal(token, c.token) {
+			return true
+		}
+	}
+	if c.user != "" {
+		if user, password, ok := r.BasicAuth(); ok && equal(user, c.user) && equal(password, c.password) {
+			return true
+		}
+	}
+	return false
+}
+
+// equal compares secrets in constant time
+func equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// requireAuth rejects requests that would change the cluster unless they carry valid credentials.
+// GET and HEAD requests only read, so they pass, which keeps the page and the app list loading.
+func requireAuth(cfg authConfig, next http.HandlerFunc) http.HandlerFunc {
+	if !cfg.enabled() {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead || cfg.authorized(r) {
+			next(w, r)
+			return
+		}
+		if cfg.user != "" {
+			// Lets browsers prompt for the credentials and retry
+			w.Header().Set("WWW-Authenticate", `Basic realm="SimpleApp Dashboard", charset="UTF-8"`)
+		}
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("requireAuth", func() {
+	var calls int
+	next := func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusOK)
+	}
+
+	BeforeEach(func() {
+		calls = 0
+	})
+
+	serve := func(cfg authConfig, req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		requireAuth(cfg, next)(rec, req)
+		return rec
+	}
+
+	It("leaves the handlers open without credentials", func() {
+		rec := serve(authConfig{}, httptest.NewRequest(http.MethodDelete, "/api/delete?name=a&namespace=b", nil))
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(calls).To(Equal(1))
+	})
+
+	Context("with basic auth", func() {
+		cfg := authConfig{user: "admin", password: "s3cret"}
+
+		It("lets read requests through", func() {
+			rec := serve(cfg, httptest.NewRequest(http.MethodGet, "/", nil))
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(calls).To(Equal(1))
+		})
+
+		It("accepts valid credentials", func() {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.SetBasicAuth("admin", "s3cret")
+			Expect(serve(cfg, req).Code).To(Equal(http.StatusOK))
+			Expect(calls).To(Equal(1))
+		})
+
+		It("challenges requests without credentials", func() {
+			rec := serve(cfg, httptest.NewRequest(http.MethodPost, "/", nil))
+			Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+			Expect(rec.Header().Get("WWW-Authenticate")).To(HavePrefix("Basic "))
+			Expect(calls).To(BeZero())
+		})
+
+		It("rejects a wrong password", func() {
+			req := httptest.NewRequest(http.MethodDelete, "/api/delete", nil)
+			req.SetBasicAuth("admin", "guess")
+			Expect(serve(cfg, req).Code).To(Equal(http.StatusUnauthorized))
+			Expect(calls).To(BeZero())
+		})
+	})
+
+	Context("with a bearer token", func() {
+		cfg := authConfig{token: "t0ken"}
+
+		It("accepts the token", func() {
+			req := httptest.NewRequest(http.MethodDelete, "/api/delete", nil)
+			req.Header.Set("Authorization", "Bearer t0ken")
+			Expect(serve(cfg, req).Code).To(Equal(http.StatusOK))
+			Expect(calls).To(Equal(1))
+		})
+
+		It("rejects another token without a basic auth challenge", func() {
+			req := httptest.NewRequest(http.MethodDelete, "/api/delete", nil)
+			req.Header.Set("Authorization", "Bearer other")
+			rec := serve(cfg, req)
+			Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+			Expect(rec.Header().Get("WWW-Authenticate")).To(BeEmpty())
+			Expect(calls).To(BeZero())
+		})
+
+		It("rejects basic auth when only a token is configured", func() {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.SetBasicAuth("", "t0ken")
+			Expect(serve(cfg, req).Code).To(Equal(http.StatusUnauthorized))
+		})
+	})
+})
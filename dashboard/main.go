@@ -1,16 +1,36 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"html/template"
+	"io"
 	"log"
+	"math"
+	"net"
 	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1 "github.com/gxanlvxgx/simple-app-operator/api/v1"
 )
 
 // PageData holds data for the HTML template (used in the POST response)
@@ -18,28 +38,96 @@ type PageData struct {
 	Message string
 	Output  string
 	Error   bool
+	Preview bool
+}
+
+// k8sClient talks to the cluster selected by -kubeconfig
+var k8sClient client.Client
+
+// clientset reads pod logs, which the controller-runtime client can't
+var clientset kubernetes.Interface
+
+const (
+	// defaultLogTail is the number of log lines shown per pod unless ?tail= asks for another
+	defaultLogTail = 100
+	// maxLogTail bounds ?tail= so a request can't pull a pod's whole log
+	maxLogTail = 1000
+	// maxLogPods bounds the number of pods whose logs a single request returns
+	maxLogPods = 5
+)
+
+// clusterTimeout bounds every cluster operation so a slow API server can't hang a request
+const clusterTimeout = 15 * time.Second
+
+// clusterContext derives the context of a request's cluster operations, cut off after clusterTimeout.
+func clusterContext(r *http.Request) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(r.Context(), clusterTimeout)
 }
 
+// readyzTimeout bounds the API server check of /readyz, below the probe's timeoutSeconds
+const readyzTimeout = 2 * time.Second
+
+// timeoutMessage is shown when the API server didn't answer within clusterTimeout
+var timeoutMessage = fmt.Sprintf("Timed out: the cluster did not respond within %s, please retry", clusterTimeout)
+
 func main() {
+	addr := flag.String("addr", ":3000", "The address the dashboard listens on.")
+	kubeconfig := flag.String("kubeconfig", "",
+		"Path to the kubeconfig file. Defaults to $KUBECONFIG, then ~/.kube/config; without one the in-cluster config is used.")
+	basicAuthUser := flag.String("basic-auth-user", os.Getenv("DASHBOARD_USER"),
+		"If set, deploying and deleting apps requires HTTP basic auth with this user and the password in $DASHBOARD_PASSWORD. "+
+			"Defaults to $DASHBOARD_USER.")
+	flag.Parse()
+
+	auth := authConfig{user: *basicAuthUser, password: os.Getenv("DASHBOARD_PASSWORD"), token: os.Getenv("DASHBOARD_TOKEN")}
+	if auth.user != "" && auth.password == "" {
+		log.Fatal("-basic-auth-user requires $DASHBOARD_PASSWORD")
+	}
+	if !auth.enabled() {
+		log.Println("WARNING: no credentials configured, anyone who can reach the dashboard can deploy and delete apps. " +
+			"Set -basic-auth-user and $DASHBOARD_PASSWORD, or $DASHBOARD_TOKEN.")
+	}
+
+	path := resolveKubeconfig(*kubeconfig)
+	if path == "" {
+		log.Println("No kubeconfig found, using the in-cluster configuration")
+	} else {
+		log.Printf("Using kubeconfig %s", path)
+	}
+	cfg, err := restConfig(path)
+	if err != nil {
+		log.Fatal("Could not load the Kubernetes configuration: ", err)
+	}
+	if k8sClient, err = newClient(cfg); err != nil {
+		log.Fatal("Could not create the Kubernetes client: ", err)
+	}
+	if clientset, err = kubernetes.NewForConfig(cfg); err != nil {
+		log.Fatal("Could not create the Kubernetes client: ", err)
+	}
+
 	// Register HTTP Handlers
-	http.HandleFunc("/", handleHome)             // Serve UI (GET) & Handle Deploy (POST)
-	http.HandleFunc("/api/list", handleList)     // API: Return JSON list of apps
-	http.HandleFunc("/api/delete", handleDelete) // API: Delete an app
+	http.HandleFunc("/", requireAuth(auth, handleHome))             // Serve UI (GET) & Handle Deploy (POST)
+	http.HandleFunc("/preview", handlePreview)                      // Render the manifest without deploying (POST)
+	http.HandleFunc("/api/list", handleList)                        // API: Return JSON list of apps
+	http.HandleFunc("/api/delete", requireAuth(auth, handleDelete)) // API: Delete an app
+	http.HandleFunc("/logs", handleLogs)                            // Show the recent logs of an app's pods (GET)
+	http.HandleFunc("/healthz", handleHealthz)                      // Liveness probe
+	http.HandleFunc("/readyz", handleReadyz)                        // Readiness probe: the API server is reachable
 
-	// Server Configuration
-	port := ":3000"
 	fmt.Println("------------------------------------------------")
-	fmt.Printf("SimpleApp Dashboard running on port %s\n", port)
+	fmt.Printf("SimpleApp Dashboard running on %s\n", *addr)
 	fmt.Println("------------------------------------------------")
 
 	// Attempt to open browser automatically (works locally, ignored in Docker)
-	go func() {
-		time.Sleep(1 * time.Second)
-		openBrowser("http://localhost" + port)
-	}()
+	if _, port, err := net.SplitHostPort(*addr); err == nil {
+		go func() {
+			time.Sleep(1 * time.Second)
+			openBrowser("http://localhost:" + port)
+		}()
+	}
 
 	// Start the Server
-	if err := http.ListenAndServe(port, nil); err != nil {
+	if err := http.ListenAndServe(*addr, nil); err != nil {
 		log.Fatal("Server failed to start:", err)
 	}
 }
@@ -61,7 +149,87 @@ func handleHome(w http.ResponseWriter, r *http.Request) {
 
 	// --- POST REQUEST: DEPLOY LOGIC ---
 
-	// 1. Retrieve Form Data
+	// 1. Retrieve and validate the form data
+	app, err := simpleAppFromForm(r)
+	if err != nil {
+		tmpl.Execute(w, PageData{Message: "Validation Error", Output: err.Error(), Error: true})
+		return
+	}
+
+	// 2. Create the SimpleApp, or update the fields the form manages if it already exists
+	ctx, cancel := clusterContext(r)
+	defer cancel()
+	action, err := deploySimpleApp(ctx, app)
+	if err != nil {
+		log.Printf("Deployment of %s/%s failed: %v", app.Namespace, app.Name, err)
+		message := "Deployment Failed"
+		switch {
+		case ctx.Err() == context.DeadlineExceeded:
+			message = timeoutMessage
+		case apierrors.IsConflict(err):
+			message = "Deployment Failed: the app was modified concurrently, please retry"
+		}
+		tmpl.Execute(w, PageData{Message: message, Output: err.Error(), Error: true})
+		return
+	}
+
+	// 3. Render the template with the result
+	tmpl.Execute(w, PageData{
+		Message: fmt.Sprintf("Application %s Successfully!", strings.ToUpper(action[:1])+action[1:]),
+		Output:  fmt.Sprintf("simpleapp.apps.myapp.io/%s %s in namespace %s", app.Name, action, app.Namespace),
+	})
+}
+
+// deploySimpleApp creates app, or updates the spec fields managed by the deploy form on the
+// existing SimpleApp. Everything else, including the resourceVersion read here, is kept, so
+// changes made outside the dashboard survive and concurrent edits fail with a conflict.
+// It returns "created" or "updated".
+func deploySimpleApp(ctx context.Context, app *appsv1.SimpleApp) (string, error) {
+	var existing appsv1.SimpleApp
+	err := k8sClient.Get(ctx, client.ObjectKeyFromObject(app), &existing)
+	if apierrors.IsNotFound(err) {
+		if err := k8sClient.Create(ctx, app); err != nil {
+			return "", err
+		}
+		return "created", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	existing.Spec.Image = app.Spec.Image
+	existing.Spec.Replicas = app.Spec.Replicas
+	existing.Spec.ContainerPort = app.Spec.ContainerPort
+	existing.Spec.ServicePort = app.Spec.ServicePort
+	if err := k8sClient.Update(ctx, &existing); err != nil {
+		return "", err
+	}
+	return "updated", nil
+}
+
+// handlePreview renders the SimpleApp manifest the deploy form would apply, without touching the cluster
+func handlePreview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tmpl, err := template.ParseFiles("index.html")
+	if err != nil {
+		http.Error(w, "Critical Error: Could not load index.html", http.StatusInternalServerError)
+		return
+	}
+
+	app, err := simpleAppFromForm(r)
+	if err != nil {
+		tmpl.Execute(w, PageData{Message: "Validation Error", Output: err.Error(), Error: true})
+		return
+	}
+	tmpl.Execute(w, PageData{Message: "Preview (nothing was deployed)", Output: manifest(app), Preview: true})
+}
+
+// simpleAppFromForm builds the SimpleApp described by the deploy form.
+func simpleAppFromForm(r *http.Request) (*appsv1.SimpleApp, error) {
 	name := strings.TrimSpace(r.FormValue("name"))
 	image := strings.TrimSpace(r.FormValue("image"))
 	replicas := strings.TrimSpace(r.FormValue("replicas"))
@@ -76,76 +244,73 @@ func handleHome(w http.ResponseWriter, r *http.Request) {
 
 	// Validate required fields
 	if name == "" || image == "" || replicas == "" || containerPort == "" || servicePort == "" {
-		tmpl.Execute(w, PageData{Message: "Validation Error: All fields are required", Output: "Missing required fields", Error: true})
-		return
+		return nil, fmt.Errorf("all fields are required")
 	}
 
-	// 2. Generate YAML content for the SimpleApp CRD
-	yamlContent := fmt.Sprintf(`apiVersion: apps.myapp.io/v1
+	app := &appsv1.SimpleApp{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec:       appsv1.SimpleAppSpec{Image: image},
+	}
+	n, err := formInt32("replicas", replicas, 0, math.MaxInt32)
+	if err != nil {
+		return nil, err
+	}
+	app.Spec.Replicas = &n
+	if app.Spec.ContainerPort, err = formInt32("containerPort", containerPort, 1, 65535); err != nil {
+		return nil, err
+	}
+	if app.Spec.ServicePort, err = formInt32("servicePort", servicePort, 1, 65535); err != nil {
+		return nil, err
+	}
+	return app, nil
+}
+
+// formInt32 parses a numeric form field and checks it lies within [lowest, highest].
+func formInt32(field, value string, lowest, highest int64) (int32, error) {
+	n, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || n < lowest || n > highest {
+		return 0, fmt.Errorf("%s must be a number between %d and %d", field, lowest, highest)
+	}
+	return int32(n), nil
+}
+
+// manifest renders the SimpleApp the deploy form would submit as YAML
+func manifest(app *appsv1.SimpleApp) string {
+	return fmt.Sprintf(`apiVersion: apps.myapp.io/v1
 kind: SimpleApp
 metadata:
   name: %s
   namespace: %s
 spec:
   image: %s
-  replicas: %s
-  containerPort: %s
-  servicePort: %s`, name, namespace, image, replicas, containerPort, servicePort)
-
-	// 3. Write YAML to a temporary file
-	absPath := filepath.Join("/tmp", name+".yaml")
-	if err := os.WriteFile(absPath, []byte(yamlContent), 0644); err != nil {
-		log.Printf("Error writing YAML file: %v", err)
-		tmpl.Execute(w, PageData{Message: "Internal Error: Could not write YAML file", Output: err.Error(), Error: true})
-		return
-	}
-	// Clean up temp file after deployment
-	defer os.Remove(absPath)
-
-	// 4. Execute 'kubectl apply'
-	cmd := exec.Command("kubectl", "apply", "-f", absPath)
-	output, err := cmd.CombinedOutput()
-	outputStr := string(output)
-
-	// 5. Prepare Response Data
-	data := PageData{Output: outputStr}
-
-	// Check if deployment was successful
-	if strings.Contains(outputStr, "created") || strings.Contains(outputStr, "configured") || strings.Contains(outputStr, "unchanged") {
-		data.Message = "Application Deployed Successfully!"
-		data.Error = false
-	} else if err != nil {
-		data.Message = "Deployment Failed"
-		data.Error = true
-	} else {
-		data.Message = "Application Deployed Successfully!"
-		data.Error = false
-	}
-
-	// 6. Render the template with the result
-	tmpl.Execute(w, data)
+  replicas: %d
+  containerPort: %d
+  servicePort: %d`, app.Name, app.Namespace, app.Spec.Image, *app.Spec.Replicas, app.Spec.ContainerPort, app.Spec.ServicePort)
 }
 
-// handleList calls 'kubectl get simpleapps' and returns the JSON output
+// handleList returns the SimpleApps of all namespaces as JSON
 func handleList(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	// Command: kubectl get simpleapps --all-namespaces -o json
-	cmd := exec.Command("kubectl", "get", "simpleapps", "-A", "-o", "json")
-	output, err := cmd.CombinedOutput()
-
-	if err != nil {
+	ctx, cancel := clusterContext(r)
+	defer cancel()
+	var apps appsv1.SimpleAppList
+	if err := k8sClient.List(ctx, &apps); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			log.Printf("Listing apps timed out: %v", err)
+			http.Error(w, timeoutMessage, http.StatusGatewayTimeout)
+			return
+		}
 		// Log the error but return a valid empty structure to frontend to prevent JS crashes
-		log.Printf("Error listing apps (CRD might not exist yet?): %s", string(output))
+		log.Printf("Error listing apps (CRD might not exist yet?): %v", err)
 		w.Write([]byte(`{"items": []}`))
 		return
 	}
 
-	// Write the raw JSON directly to the response
-	w.Write(output)
+	json.NewEncoder(w).Encode(apps)
 }
 
-// handleDelete calls 'kubectl delete simpleapp' for a specific resource
+// handleDelete deletes a specific SimpleApp
 func handleDelete(w http.ResponseWriter, r *http.Request) {
 	// Only allow DELETE method
 	if r.Method != http.MethodDelete {
@@ -164,13 +329,11 @@ func handleDelete(w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("Request to delete app: %s in namespace: %s", name, namespace)
 
-	// Command: kubectl delete simpleapp <name> -n <namespace>
-	cmd := exec.Command("kubectl", "delete", "simpleapp", name, "-n", namespace)
-	output, err := cmd.CombinedOutput()
-
-	if err != nil {
-		log.Printf("Delete failed: %s", string(output))
-		http.Error(w, "Failed to delete resource: "+string(output), http.StatusInternalServerError)
+	ctx, cancel := clusterContext(r)
+	defer cancel()
+	app := &appsv1.SimpleApp{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	if err := k8sClient.Delete(ctx, app); err != nil {
+		clusterError(ctx, w, "Failed to delete resource", err)
 		return
 	}
 
@@ -179,6 +342,158 @@ func handleDelete(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("Resource deleted successfully"))
 }
 
+// handleLogs returns the last lines of the app container's log of each pod of a SimpleApp as plain text
+func handleLogs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	namespace := r.URL.Query().Get("namespace")
+	if namespace == "" {
+		namespace = "default"
+	}
+	if name == "" {
+		http.Error(w, "Missing 'name' parameter", http.StatusBadRequest)
+		return
+	}
+	tail := int64(defaultLogTail)
+	if value := r.URL.Query().Get("tail"); value != "" {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || n < 1 || n > maxLogTail {
+			http.Error(w, fmt.Sprintf("tail must be a number between 1 and %d", maxLogTail), http.StatusBadRequest)
+			return
+		}
+		tail = n
+	}
+
+	ctx, cancel := clusterContext(r)
+	defer cancel()
+	var app appsv1.SimpleApp
+	if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &app); err != nil {
+		clusterError(ctx, w, "Failed to get app", err)
+		return
+	}
+	container := app.Spec.ContainerName
+	if container == "" {
+		container = "app"
+	}
+
+	selector := client.MatchingLabels{"app": name}
+	if len(app.Spec.SelectorLabels) > 0 {
+		selector = app.Spec.SelectorLabels
+	}
+	var pods corev1.PodList
+	if err := k8sClient.List(ctx, &pods, client.InNamespace(namespace), selector); err != nil {
+		clusterError(ctx, w, "Failed to list pods", err)
+		return
+	}
+	if len(pods.Items) == 0 {
+		http.Error(w, fmt.Sprintf("No pods found for app %s in namespace %s", name, namespace), http.StatusNotFound)
+		return
+	}
+	sort.Slice(pods.Items, func(i, j int) bool { return pods.Items[i].Name < pods.Items[j].Name })
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if len(pods.Items) > maxLogPods {
+		fmt.Fprintf(w, "Showing %d of %d pods\n\n", maxLogPods, len(pods.Items))
+		pods.Items = pods.Items[:maxLogPods]
+	}
+	for _, pod := range pods.Items {
+		fmt.Fprintf(w, "==> %s <==\n", pod.Name)
+		opts := &corev1.PodLogOptions{Container: container, TailLines: &tail}
+		stream, err := clientset.CoreV1().Pods(namespace).GetLogs(pod.Name, opts).Stream(ctx)
+		if err != nil {
+			// A pod that isn't running yet has no log; keep going with the others
+			fmt.Fprintf(w, "(no logs: %v)\n\n", err)
+			continue
+		}
+		_, err = io.Copy(w, stream)
+		stream.Close()
+		if err != nil {
+			log.Printf("Streaming the logs of pod %s/%s failed: %v", namespace, pod.Name, err)
+			return
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// clusterError reports a failed cluster operation, distinguishing timeouts and missing objects
+func clusterError(ctx context.Context, w http.ResponseWriter, message string, err error) {
+	log.Printf("%s: %v", message, err)
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		http.Error(w, timeoutMessage, http.StatusGatewayTimeout)
+	case apierrors.IsNotFound(err):
+		http.Error(w, message+": "+err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, message+": "+err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// handleHealthz reports that the dashboard is serving requests
+func handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("ok"))
+}
+
+// handleReadyz reports whether the API server can be reached, so a dashboard that can't list or
+// deploy apps is taken out of its Service
+func handleReadyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readyzTimeout)
+	defer cancel()
+	if err := clientset.Discovery().RESTClient().Get().AbsPath("/version").Do(ctx).Error(); err != nil {
+		log.Printf("Readiness check failed: %v", err)
+		http.Error(w, "API server unreachable", http.StatusServiceUnavailable)
+		return
+	}
+	w.Write([]byte("ok"))
+}
+
+// resolveKubeconfig picks the kubeconfig to use: the flag, then $KUBECONFIG, then ~/.kube/config
+// if it exists. An empty result means the in-cluster service account is used.
+func resolveKubeconfig(path string) string {
+	if path != "" {
+		return path
+	}
+	if env := os.Getenv("KUBECONFIG"); env != "" {
+		return env
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		if def := filepath.Join(home, ".kube", "config"); fileExists(def) {
+			return def
+		}
+	}
+	return ""
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// restConfig loads the cluster configuration from a kubeconfig, which may be a list of files
+// like $KUBECONFIG, or from the in-cluster configuration when kubeconfig is empty.
+func restConfig(kubeconfig string) (*rest.Config, error) {
+	if kubeconfig == "" {
+		return rest.InClusterConfig()
+	}
+	rules := &clientcmd.ClientConfigLoadingRules{Precedence: filepath.SplitList(kubeconfig)}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{}).ClientConfig()
+}
+
+// newClient creates a client for the SimpleApp API and the built-in kinds.
+func newClient(cfg *rest.Config) (client.Client, error) {
+	scheme := k8sruntime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := appsv1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	return client.New(cfg, client.Options{Scheme: scheme})
+}
+
 // openBrowser attempts to launch the default system browser
 func openBrowser(url string) {
 	var err error
@@ -193,4 +508,4 @@ func openBrowser(url string) {
 	if err != nil {
 		// Expected error inside Docker containers (no GUI), so we just ignore it.
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1alpha1 "github.com/gxanlvxgx/simple-app-operator/api/v1"
+	"github.com/gxanlvxgx/simple-app-operator/internal/metrics"
+)
+
+// defaultTargetCPUUtilization matches the CRD default of spec.autoscaling.targetCPUUtilizationPercentage.
+const defaultTargetCPUUtilization = 80
+
+// ensureHPA creates or updates the HorizontalPodAutoscaler scaling the Deployment,
+// and deletes it once spec.autoscaling is removed.
+func (r *SimpleAppReconciler) ensureHPA(ctx context.Context, cr *appsv1alpha1.SimpleApp, name string) error {
+	var existing autoscalingv2.HorizontalPodAutoscaler
+	err := r.Get(ctx, client.ObjectKey{Name: name, Namespace: cr.Namespace}, &existing)
+	if client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	found := err == nil
+
+	if cr.Spec.Autoscaling == nil {
+		if found && metav1.IsControlledBy(&existing, cr) {
+			if err := r.Delete(ctx, &existing); client.IgnoreNotFound(err) != nil {
+				return err
+			}
+			metrics.RecordChildOperation("HorizontalPodAutoscaler", metrics.OperationDelete)
+		}
+		return nil
+	}
+
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: cr.Namespace,
+			Labels:    r.childLabels(cr),
+		},
+		Spec: desiredHPASpec(cr, name),
+	}
+	if err := ctrl.SetControllerReference(cr, hpa, r.Scheme); err != nil {
+		return err
+	}
+
+	if !found {
+		if found, err = r.childExists(ctx, client.ObjectKeyFromObject(hpa), &existing); err != nil {
+			return err
+		}
+	}
+	if !found {
+		if err := r.Create(ctx, hpa); err != nil {
+			return err
+		}
+		metrics.RecordChildOperation("HorizontalPodAutoscaler", metrics.OperationCreate)
+		return nil
+	}
+
+	// Behavior is defaulted by the API server and left to users
+	if !equality.Semantic.DeepEqual(existing.Spec.ScaleTargetRef, hpa.Spec.ScaleTargetRef) ||
+		!equality.Semantic.DeepEqual(existing.Spec.MinReplicas, hpa.Spec.MinReplicas) ||
+		existing.Spec.MaxReplicas != hpa.Spec.MaxReplicas ||
+		!equality.Semantic.DeepEqual(existing.Spec.Metrics, hpa.Spec.Metrics) ||
+		!hasLabels(existing.Labels, hpa.Labels) {
+		existing.Spec.ScaleTargetRef = hpa.Spec.ScaleTargetRef
+		existing.Spec.MinReplicas = hpa.Spec.MinReplicas
+		existing.Spec.MaxReplicas = hpa.Spec.MaxReplicas
+		existing.Spec.Metrics = hpa.Spec.Metrics
+		for k, v := range hpa.Labels {
+			metav1.SetMetaDataLabel(&existing.ObjectMeta, k, v)
+		}
+		if err := r.Update(ctx, &existing); err != nil {
+			return err
+		}
+		metrics.RecordChildOperation("HorizontalPodAutoscaler", metrics.OperationUpdate)
+	}
+	return nil
+}
+
+// desiredHPASpec scales the Deployment on the CPU usage of a single container, so sidecars
+// don't skew the average utilization.
+func desiredHPASpec(cr *appsv1alpha1.SimpleApp, name string) autoscalingv2.HorizontalPodAutoscalerSpec {
+	as := cr.Spec.Autoscaling
+
+	container := as.ScaleTargetContainer
+	if container == "" {
+		container = containerName(cr)
+	}
+	target := as.TargetCPUUtilizationPercentage
+	if target == 0 {
+		target = defaultTargetCPUUtilization
+	}
+	// Mirror the API server default so the comparison stays stable
+	minReplicas := ptr.To(int32(1))
+	if as.MinReplicas != nil {
+		minReplicas = ptr.To(*as.MinReplicas)
+	}
+
+	return autoscalingv2.HorizontalPodAutoscalerSpec{
+		ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+			APIVersion: "apps/v1",
+			Kind:       "Deployment",
+			Name:       name,
+		},
+		MinReplicas: minReplicas,
+		MaxReplicas: as.MaxReplicas,
+		Metrics: []autoscalingv2.MetricSpec{{
+			Type: autoscalingv2.ContainerResourceMetricSourceType,
+			ContainerResource: &autoscalingv2.ContainerResourceMetricSource{
+				Name:      corev1.ResourceCPU,
+				Container: container,
+				Target: autoscalingv2.MetricTarget{
+					Type:               autoscalingv2.UtilizationMetricType,
+					AverageUtilization: ptr.To(target),
+				},
+			},
+		}},
+	}
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	appsv1alpha1 "github.com/gxanlvxgx/simple-app-operator/api/v1"
+	"github.com/gxanlvxgx/simple-app-operator/internal/metrics"
+)
+
+// canaryTrackLabel tells the canary pods apart from the stable ones, which don't carry it: the
+// selector of the stable Deployment can't change and already matches both.
+const canaryTrackLabel = "apps.myapp.io/track"
+
+// canaryName returns the name of the canary Deployment of spec.canary.
+func canaryName(name string) string {
+	return name + "-canary"
+}
+
+// canaryReplicas returns how many of the total replicas run the canary: weight percent of them,
+// rounded to the nearest replica, but at least one canary and one stable replica. A single replica
+// is not split, so it stays on the stable image.
+func canaryReplicas(total, weight int32) int32 {
+	if total < 2 {
+		return 0
+	}
+	canary := (total*weight + 50) / 100
+	return min(max(canary, 1), total-1)
+}
+
+// ensureCanaryDeployment creates or updates the canary Deployment of spec.canary: the app's pod
+// template with the canary image, labelled so the Service routes to it alongside the stable pods.
+// It returns nil without spec.canary; a canary Deployment left behind is then removed with the
+// other stale children. total is the replicaCount of the app, split with the stable Deployment.
+func (r *SimpleAppReconciler) ensureCanaryDeployment(ctx context.Context, cr *appsv1alpha1.SimpleApp, name string, total int32) (*appsv1.Deployment, error) {
+	if cr.Spec.Canary == nil {
+		return nil, nil
+	}
+	log := logf.FromContext(ctx).WithValues("Deployment", canaryName(name), "Namespace", cr.Namespace)
+	replicas := canaryReplicas(total, cr.Spec.Canary.Weight)
+
+	template := podTemplate(cr, name)
+	template.Labels[canaryTrackLabel] = "canary"
+	if i := containerIndex(template.Spec.Containers, containerName(cr)); i >= 0 {
+		template.Spec.Containers[i].Image = cr.Spec.Canary.Image
+	}
+	labels := r.deploymentLabels(cr)
+	labels[canaryTrackLabel] = "canary"
+	selector := appLabels(cr)
+	selector[canaryTrackLabel] = "canary"
+	dep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      canaryName(name),
+			Namespace: cr.Namespace,
+			Labels:    labels,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas:                &replicas,
+			ProgressDeadlineSeconds: progressDeadlineSeconds(cr),
+			Paused:                  cr.Spec.Paused,
+			Selector:                &metav1.LabelSelector{MatchLabels: selector},
+			Template:                template,
+		},
+	}
+	metav1.SetMetaDataAnnotation(&dep.ObjectMeta, specHashAnnotation, specHash(dep.Spec.DeepCopy()))
+	if err := ctrl.SetControllerReference(cr, dep, r.Scheme); err != nil {
+		return nil, err
+	}
+
+	var existing appsv1.Deployment
+	err := r.getChild(ctx, client.ObjectKeyFromObject(dep), &existing)
+	if err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return nil, err
+		}
+		log.V(1).Info("Creating canary Deployment", "Replicas", replicas)
+		if err := r.Create(ctx, dep); err != nil {
+			return nil, err
+		}
+		metrics.RecordChildOperation("Deployment", metrics.OperationCreate)
+		return dep, nil
+	}
+
+	// getChild again, since the Deployment may only be known to the API server so far
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := r.getChild(ctx, client.ObjectKeyFromObject(dep), &existing); err != nil {
+			return err
+		}
+		patch := client.MergeFromWithOptions(existing.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		changed := syncDeployment(&existing, dep)
+		if len(changed) == 0 {
+			log.V(1).Info("Canary Deployment up to date")
+			return nil
+		}
+		log.V(1).Info("Updating canary Deployment", "Changed", changed)
+		if err := r.Patch(ctx, &existing, patch); err != nil {
+			return err
+		}
+		metrics.RecordChildOperation("Deployment", metrics.OperationUpdate)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &existing, nil
+}
+
+// canaryStatus reports the replicas and health of the canary Deployment.
+func (r *SimpleAppReconciler) canaryStatus(ctx context.Context, cr *appsv1alpha1.SimpleApp, canary *appsv1.Deployment) (*appsv1alpha1.CanaryStatus, error) {
+	pods, _, err := r.appPods(ctx, cr, canary)
+	if err != nil {
+		return nil, err
+	}
+	return &appsv1alpha1.CanaryStatus{
+		Image:         runningImage(cr, canary),
+		Replicas:      *canary.Spec.Replicas,
+		ReadyReplicas: canary.Status.ReadyReplicas,
+		Message:       rolloutMessage(canary, pods),
+	}, nil
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	k8sappsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appsv1 "github.com/gxanlvxgx/simple-app-operator/api/v1"
+)
+
+var _ = Describe("canaryReplicas", func() {
+	DescribeTable("splits the replicas while keeping both tracks",
+		func(total, weight, canary int32) {
+			Expect(canaryReplicas(total, weight)).To(Equal(canary))
+		},
+		Entry("a quarter of four", int32(4), int32(25), int32(1)),
+		Entry("rounding to the nearest replica", int32(10), int32(15), int32(2)),
+		Entry("at least one canary", int32(10), int32(1), int32(1)),
+		Entry("at least one stable replica", int32(3), int32(99), int32(2)),
+		Entry("a single replica is not split", int32(1), int32(50), int32(0)),
+		Entry("scaled to zero", int32(0), int32(50), int32(0)),
+	)
+})
+
+var _ = Describe("Canary rollouts", func() {
+	var (
+		reconciler *SimpleAppReconciler
+		app        *appsv1.SimpleApp
+		key        = client.ObjectKey{Name: "web", Namespace: "default"}
+		canaryKey  = client.ObjectKey{Name: "web-canary", Namespace: "default"}
+	)
+
+	reconcileApp := func() {
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		ExpectWithOffset(1, reconciler.Get(ctx, key, app)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		reconciler = &SimpleAppReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).
+				WithStatusSubresource(&appsv1.SimpleApp{}, &k8sappsv1.Deployment{}).Build(),
+			Scheme:   scheme.Scheme,
+			Recorder: record.NewFakeRecorder(100),
+		}
+		app = &appsv1.SimpleApp{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+			Spec: appsv1.SimpleAppSpec{
+				Image:         "nginx:1.25",
+				Replicas:      ptr.To[int32](4),
+				ContainerPort: 8080,
+				Canary:        &appsv1.CanarySpec{Image: "nginx:1.27", Weight: 25},
+			},
+		}
+		Expect(reconciler.Create(ctx, app)).To(Succeed())
+	})
+
+	It("should run the canary share of the replicas behind the same Service", func() {
+		reconcileApp()
+
+		stable := &k8sappsv1.Deployment{}
+		Expect(reconciler.Get(ctx, key, stable)).To(Succeed())
+		Expect(stable.Spec.Replicas).To(HaveValue(Equal(int32(3))))
+		Expect(stable.Spec.Template.Spec.Containers[0].Image).To(Equal("nginx:1.25"))
+
+		canary := &k8sappsv1.Deployment{}
+		Expect(reconciler.Get(ctx, canaryKey, canary)).To(Succeed())
+		Expect(metav1.IsControlledBy(canary, app)).To(BeTrue())
+		Expect(canary.Spec.Replicas).To(HaveValue(Equal(int32(1))))
+		Expect(canary.Spec.Template.Spec.Containers[0].Image).To(Equal("nginx:1.27"))
+		Expect(canary.Spec.Selector.MatchLabels).To(HaveKeyWithValue("apps.myapp.io/track", "canary"))
+
+		service := &corev1.Service{}
+		Expect(reconciler.Get(ctx, key, service)).To(Succeed())
+		for k, v := range service.Spec.Selector {
+			Expect(canary.Spec.Template.Labels).To(HaveKeyWithValue(k, v))
+		}
+
+		Expect(app.Status.Canary).To(Equal(&appsv1.CanaryStatus{Image: "nginx:1.27", Replicas: 1}))
+	})
+
+	It("should not report the canary pods as foreign", func() {
+		reconcileApp()
+
+		// The fake client assigns no UIDs, which would make every Deployment look like the owner
+		stable := &k8sappsv1.Deployment{}
+		Expect(reconciler.Get(ctx, key, stable)).To(Succeed())
+		stable.UID = "stable"
+		Expect(reconciler.Update(ctx, stable)).To(Succeed())
+		canary := &k8sappsv1.Deployment{}
+		Expect(reconciler.Get(ctx, canaryKey, canary)).To(Succeed())
+		canary.UID = "canary"
+		Expect(reconciler.Update(ctx, canary)).To(Succeed())
+		replicaSet := &k8sappsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "web-canary-abc",
+				Namespace: key.Namespace,
+				UID:       "canary-abc",
+				Labels:    canary.Spec.Template.Labels,
+				OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(canary,
+					k8sappsv1.SchemeGroupVersion.WithKind("Deployment"))},
+			},
+			Spec: k8sappsv1.ReplicaSetSpec{Selector: canary.Spec.Selector, Template: canary.Spec.Template},
+		}
+		Expect(reconciler.Create(ctx, replicaSet)).To(Succeed())
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "web-canary-abc-x1",
+				Namespace: key.Namespace,
+				Labels:    canary.Spec.Template.Labels,
+				OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(replicaSet,
+					k8sappsv1.SchemeGroupVersion.WithKind("ReplicaSet"))},
+			},
+			Spec: canary.Spec.Template.Spec,
+		}
+		Expect(reconciler.Create(ctx, pod)).To(Succeed())
+
+		reconcileApp()
+		Expect(meta.IsStatusConditionFalse(app.Status.Conditions, appsv1.ConditionSelectorCollision)).To(BeTrue())
+	})
+
+	It("should hand the replicas back to the stable Deployment when the canary ends", func() {
+		reconcileApp()
+
+		app.Spec.Canary = nil
+		Expect(reconciler.Update(ctx, app)).To(Succeed())
+		reconcileApp()
+
+		Expect(errors.IsNotFound(reconciler.Get(ctx, canaryKey, &k8sappsv1.Deployment{}))).To(BeTrue())
+		stable := &k8sappsv1.Deployment{}
+		Expect(reconciler.Get(ctx, key, stable)).To(Succeed())
+		Expect(stable.Spec.Replicas).To(HaveValue(Equal(int32(4))))
+		Expect(app.Status.Canary).To(BeNil())
+	})
+})
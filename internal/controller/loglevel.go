@@ -0,0 +1,92 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+)
+
+// parseLogLevel maps the value of the log-level annotation to the highest logr
+// V-level that should be emitted for the object. "error" silences info logs entirely.
+func parseLogLevel(value string) (int, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "error":
+		return -1, nil
+	case "info":
+		return 0, nil
+	case "debug":
+		return 1, nil
+	case "trace":
+		return 2, nil
+	}
+
+	level, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || level < 0 {
+		return 0, fmt.Errorf("invalid log level %q: expected error, info, debug, trace or a non-negative V-level", value)
+	}
+	return level, nil
+}
+
+// withVerbosity returns a logger that emits info logs up to the given V-level,
+// regardless of the verbosity the underlying sink was configured with.
+func withVerbosity(logger logr.Logger, verbosity int) logr.Logger {
+	sink := logger.GetSink()
+	if sink == nil {
+		return logger
+	}
+	// Account for the extra frame added by verbositySink so callers are reported correctly
+	if cd, ok := sink.(logr.CallDepthLogSink); ok {
+		sink = cd.WithCallDepth(1)
+	}
+	return logr.New(verbositySink{LogSink: sink, verbosity: verbosity})
+}
+
+// verbositySink overrides the verbosity decision of the wrapped sink. Enabled entries
+// are forwarded at V(0) so the wrapped sink doesn't filter them out again.
+type verbositySink struct {
+	logr.LogSink
+	verbosity int
+}
+
+var _ logr.CallDepthLogSink = verbositySink{}
+
+func (s verbositySink) Enabled(level int) bool {
+	return level <= s.verbosity
+}
+
+func (s verbositySink) Info(_ int, msg string, keysAndValues ...any) {
+	s.LogSink.Info(0, msg, keysAndValues...)
+}
+
+func (s verbositySink) WithValues(keysAndValues ...any) logr.LogSink {
+	return verbositySink{LogSink: s.LogSink.WithValues(keysAndValues...), verbosity: s.verbosity}
+}
+
+func (s verbositySink) WithName(name string) logr.LogSink {
+	return verbositySink{LogSink: s.LogSink.WithName(name), verbosity: s.verbosity}
+}
+
+func (s verbositySink) WithCallDepth(depth int) logr.LogSink {
+	if cd, ok := s.LogSink.(logr.CallDepthLogSink); ok {
+		return verbositySink{LogSink: cd.WithCallDepth(depth), verbosity: s.verbosity}
+	}
+	return s
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// capturingLogger returns a logger configured at the given verbosity that records every emitted line.
+func capturingLogger(verbosity int) (logr.Logger, *[]string) {
+	var lines []string
+	logger := funcr.New(func(prefix, args string) {
+		lines = append(lines, args)
+	}, funcr.Options{Verbosity: verbosity})
+	return logger, &lines
+}
+
+var _ = Describe("Per-object log level", func() {
+	DescribeTable("parsing the annotation",
+		func(value string, expected int) {
+			level, err := parseLogLevel(value)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(level).To(Equal(expected))
+		},
+		Entry("error silences info logs", "error", -1),
+		Entry("info", "info", 0),
+		Entry("debug", "DEBUG", 1),
+		Entry("trace", "trace", 2),
+		Entry("numeric V-level", "4", 4),
+	)
+
+	DescribeTable("rejecting invalid values",
+		func(value string) {
+			_, err := parseLogLevel(value)
+			Expect(err).To(MatchError(ContainSubstring("invalid log level")))
+		},
+		Entry("unknown name", "verbose"),
+		Entry("negative level", "-2"),
+		Entry("empty", ""),
+	)
+
+	It("raises the verbosity above the sink's configuration", func() {
+		base, lines := capturingLogger(0)
+		logger := withVerbosity(base, 1)
+
+		logger.V(1).Info("debug line")
+		logger.V(2).Info("trace line")
+		Expect(*lines).To(ConsistOf(ContainSubstring("debug line")))
+	})
+
+	It("silences info logs but keeps errors at the error level", func() {
+		base, lines := capturingLogger(0)
+		logger := withVerbosity(base, -1).WithValues("app", "web")
+
+		logger.Info("info line")
+		logger.Error(nil, "error line")
+		Expect(*lines).To(ConsistOf(And(ContainSubstring("error line"), ContainSubstring(`"app"="web"`))))
+	})
+})
@@ -0,0 +1,123 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"strings"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	appsv1alpha1 "github.com/gxanlvxgx/simple-app-operator/api/v1"
+)
+
+// nameTemplateData is the data exposed to SimpleAppSpec.NameTemplate.
+type nameTemplateData struct {
+	Name      string
+	Namespace string
+	Labels    map[string]string
+}
+
+// childName computes the name shared by the Deployment and Service of a SimpleApp.
+// Without a NameTemplate the CR name is used, as before.
+func childName(cr *appsv1alpha1.SimpleApp) (string, error) {
+	if cr.Spec.NameTemplate == "" {
+		return cr.Name, nil
+	}
+
+	// missingkey=error makes a reference to an absent label fail loudly instead of rendering "<no value>"
+	tmpl, err := template.New("nameTemplate").Option("missingkey=error").Parse(cr.Spec.NameTemplate)
+	if err != nil {
+		return "", fmt.Errorf("invalid nameTemplate: %w", err)
+	}
+
+	var sb strings.Builder
+	data := nameTemplateData{Name: cr.Name, Namespace: cr.Namespace, Labels: cr.Labels}
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("rendering nameTemplate: %w", err)
+	}
+
+	name := sb.String()
+	if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+		return "", fmt.Errorf("nameTemplate rendered %q, which is not a valid DNS-1123 name: %s", name, strings.Join(errs, "; "))
+	}
+	return name, nil
+}
+
+// appLabels returns the labels the selectors pointing at the app's pods match: spec.selectorLabels,
+// or the app label.
+func appLabels(cr *appsv1alpha1.SimpleApp) map[string]string {
+	if len(cr.Spec.SelectorLabels) > 0 {
+		return maps.Clone(cr.Spec.SelectorLabels)
+	}
+	return map[string]string{"app": appLabelValue(cr)}
+}
+
+// podLabels returns the labels of the app's pods: the app label plus spec.selectorLabels, which
+// win over it.
+func podLabels(cr *appsv1alpha1.SimpleApp) map[string]string {
+	labels := map[string]string{"app": appLabelValue(cr)}
+	maps.Copy(labels, cr.Spec.SelectorLabels)
+	return labels
+}
+
+// childLabels returns the metadata labels of the Deployment and Service: the common labels
+// configured on the controller plus the labels of the pods.
+func (r *SimpleAppReconciler) childLabels(cr *appsv1alpha1.SimpleApp) map[string]string {
+	labels := make(map[string]string, len(r.CommonLabels)+1)
+	for k, v := range r.CommonLabels {
+		labels[k] = v
+	}
+	for k, v := range podLabels(cr) {
+		labels[k] = v
+	}
+	return labels
+}
+
+// deploymentLabels returns the metadata labels of the Deployments: spec.deploymentLabels plus
+// the labels of the other children, which win over them.
+func (r *SimpleAppReconciler) deploymentLabels(cr *appsv1alpha1.SimpleApp) map[string]string {
+	labels := maps.Clone(cr.Spec.DeploymentLabels)
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	maps.Copy(labels, r.childLabels(cr))
+	return labels
+}
+
+// appLabelValue returns the value of the "app" label for a SimpleApp. Object names may be up to
+// 253 characters long while label values are limited to 63, so longer names are truncated and
+// suffixed with a hash of the full name to stay unique. Names that are valid label values are used
+// as-is, which keeps existing (immutable) Deployment selectors unchanged.
+func appLabelValue(cr *appsv1alpha1.SimpleApp) string {
+	if len(validation.IsValidLabelValue(cr.Name)) == 0 {
+		return cr.Name
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(cr.Name))
+	suffix := fmt.Sprintf("%08x", h.Sum32())
+	prefix := cr.Name
+	if n := validation.LabelValueMaxLength - len(suffix) - 1; len(prefix) > n {
+		prefix = prefix[:n]
+	}
+	// Label values must start and end with an alphanumeric character
+	return strings.TrimRight(prefix, "-.") + "-" + suffix
+}
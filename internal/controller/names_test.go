@@ -0,0 +1,90 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	appsv1 "github.com/gxanlvxgx/simple-app-operator/api/v1"
+)
+
+var _ = Describe("childName", func() {
+	newApp := func(nameTemplate string, labels map[string]string) *appsv1.SimpleApp {
+		return &appsv1.SimpleApp{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop", Labels: labels},
+			Spec:       appsv1.SimpleAppSpec{NameTemplate: nameTemplate},
+		}
+	}
+
+	It("defaults to the SimpleApp name", func() {
+		name, err := childName(newApp("", nil))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(name).To(Equal("web"))
+	})
+
+	It("renders name, namespace and labels", func() {
+		name, err := childName(newApp("{{ .Labels.env }}-{{ .Namespace }}-{{ .Name }}", map[string]string{"env": "prod"}))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(name).To(Equal("prod-shop-web"))
+	})
+
+	It("rejects templates that do not parse", func() {
+		_, err := childName(newApp("{{ .Name", nil))
+		Expect(err).To(MatchError(ContainSubstring("invalid nameTemplate")))
+	})
+
+	It("rejects references to missing labels", func() {
+		_, err := childName(newApp("{{ .Labels.env }}-{{ .Name }}", nil))
+		Expect(err).To(MatchError(ContainSubstring("rendering nameTemplate")))
+	})
+
+	It("rejects results that are not DNS-1123 labels", func() {
+		_, err := childName(newApp("{{ .Name }}_{{ .Labels.env }}", map[string]string{"env": "Prod"}))
+		Expect(err).To(MatchError(ContainSubstring("not a valid DNS-1123 name")))
+	})
+})
+
+var _ = Describe("appLabelValue", func() {
+	newApp := func(name string) *appsv1.SimpleApp {
+		return &appsv1.SimpleApp{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+
+	It("keeps names that are valid label values, dots included", func() {
+		Expect(appLabelValue(newApp("web.v1"))).To(Equal("web.v1"))
+	})
+
+	It("shortens names longer than a label value allows", func() {
+		long := "shop.example.com-" + strings.Repeat("a", 60)
+		value := appLabelValue(newApp(long))
+		Expect(validation.IsValidLabelValue(value)).To(BeEmpty())
+		Expect(value).To(HavePrefix("shop.example.com-"))
+
+		By("keeping names that only differ past the cut apart")
+		Expect(appLabelValue(newApp(long + "b"))).NotTo(Equal(value))
+	})
+
+	It("does not end on a separator after truncating", func() {
+		value := appLabelValue(newApp(strings.Repeat("a", 53) + "." + strings.Repeat("b", 20)))
+		Expect(validation.IsValidLabelValue(value)).To(BeEmpty())
+	})
+})
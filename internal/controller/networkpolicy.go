@@ -0,0 +1,114 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	appsv1alpha1 "github.com/gxanlvxgx/simple-app-operator/api/v1"
+	"github.com/gxanlvxgx/simple-app-operator/internal/metrics"
+)
+
+// networkPolicyPorts returns the pod ports the NetworkPolicy admits traffic to: the container port
+// and, when it is a port of its own, the metrics port.
+func networkPolicyPorts(cr *appsv1alpha1.SimpleApp) []networkingv1.NetworkPolicyPort {
+	var ports []networkingv1.NetworkPolicyPort
+	for _, p := range containerPorts(cr) {
+		port := intstr.FromInt32(p.ContainerPort)
+		ports = append(ports, networkingv1.NetworkPolicyPort{Protocol: &p.Protocol, Port: &port})
+	}
+	return ports
+}
+
+// ensureNetworkPolicy creates or updates the NetworkPolicy restricting ingress to the app's pods,
+// and deletes it once spec.networkPolicy is removed. It selects the canary pods too.
+func (r *SimpleAppReconciler) ensureNetworkPolicy(ctx context.Context, cr *appsv1alpha1.SimpleApp, name string) error {
+	log := logf.FromContext(ctx).WithValues("NetworkPolicy", name, "Namespace", cr.Namespace)
+
+	var existing networkingv1.NetworkPolicy
+	err := r.Get(ctx, client.ObjectKey{Name: name, Namespace: cr.Namespace}, &existing)
+	if client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	found := err == nil
+
+	if cr.Spec.NetworkPolicy == nil {
+		if found && metav1.IsControlledBy(&existing, cr) {
+			log.V(1).Info("Deleting NetworkPolicy", "Reason", "networkPolicy is unset")
+			if err := r.Delete(ctx, &existing); client.IgnoreNotFound(err) != nil {
+				return err
+			}
+			metrics.RecordChildOperation("NetworkPolicy", metrics.OperationDelete)
+		}
+		return nil
+	}
+
+	policy := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: cr.Namespace,
+			Labels:    r.childLabels(cr),
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: appLabels(cr)},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{{
+				Ports: networkPolicyPorts(cr),
+				From:  cr.Spec.NetworkPolicy.From,
+			}},
+		},
+	}
+	if err := ctrl.SetControllerReference(cr, policy, r.Scheme); err != nil {
+		return err
+	}
+
+	if !found {
+		if found, err = r.childExists(ctx, client.ObjectKeyFromObject(policy), &existing); err != nil {
+			return err
+		}
+	}
+	if !found {
+		log.V(1).Info("Creating NetworkPolicy")
+		if err := r.Create(ctx, policy); err != nil {
+			return err
+		}
+		metrics.RecordChildOperation("NetworkPolicy", metrics.OperationCreate)
+		return nil
+	}
+
+	if equality.Semantic.DeepEqual(existing.Spec, policy.Spec) {
+		return nil
+	}
+	log.V(1).Info("Updating NetworkPolicy")
+	existing.Spec = policy.Spec
+	for k, v := range policy.Labels {
+		metav1.SetMetaDataLabel(&existing.ObjectMeta, k, v)
+	}
+	if err := r.Update(ctx, &existing); err != nil {
+		return err
+	}
+	metrics.RecordChildOperation("NetworkPolicy", metrics.OperationUpdate)
+	return nil
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	k8sappsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appsv1 "github.com/gxanlvxgx/simple-app-operator/api/v1"
+)
+
+var _ = Describe("NetworkPolicy", func() {
+	var (
+		reconciler *SimpleAppReconciler
+		app        *appsv1.SimpleApp
+		key        = client.ObjectKey{Name: "api", Namespace: "default"}
+		frontend   = networkingv1.NetworkPolicyPeer{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "web"}},
+			PodSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{"role": "frontend"}},
+		}
+	)
+
+	reconcileApp := func() {
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		ExpectWithOffset(1, reconciler.Get(ctx, key, app)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		reconciler = &SimpleAppReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).
+				WithStatusSubresource(&appsv1.SimpleApp{}, &k8sappsv1.Deployment{}).Build(),
+			Scheme:   scheme.Scheme,
+			Recorder: record.NewFakeRecorder(100),
+		}
+		app = &appsv1.SimpleApp{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+			Spec: appsv1.SimpleAppSpec{
+				Image:         "registry.example.com/api:v1",
+				Replicas:      ptr.To[int32](2),
+				ContainerPort: 8080,
+				Metrics:       &appsv1.MetricsSpec{Port: 9090},
+				NetworkPolicy: &appsv1.NetworkPolicySpec{From: []networkingv1.NetworkPolicyPeer{frontend}},
+			},
+		}
+		Expect(reconciler.Create(ctx, app)).To(Succeed())
+	})
+
+	It("should only admit the listed sources on the app's ports", func() {
+		reconcileApp()
+
+		policy := &networkingv1.NetworkPolicy{}
+		Expect(reconciler.Get(ctx, key, policy)).To(Succeed())
+		Expect(metav1.IsControlledBy(policy, app)).To(BeTrue())
+		Expect(policy.Spec.PodSelector.MatchLabels).To(Equal(map[string]string{"app": "api"}))
+		Expect(policy.Spec.PolicyTypes).To(ConsistOf(networkingv1.PolicyTypeIngress))
+		Expect(policy.Spec.Ingress).To(HaveLen(1))
+		Expect(policy.Spec.Ingress[0].From).To(ConsistOf(frontend))
+		tcp := corev1.ProtocolTCP
+		Expect(policy.Spec.Ingress[0].Ports).To(ConsistOf(
+			networkingv1.NetworkPolicyPort{Protocol: &tcp, Port: ptr.To(intstr.FromInt32(8080))},
+			networkingv1.NetworkPolicyPort{Protocol: &tcp, Port: ptr.To(intstr.FromInt32(9090))},
+		))
+	})
+
+	It("should follow changes to the sources and the port", func() {
+		reconcileApp()
+
+		app.Spec.NetworkPolicy.From = nil
+		app.Spec.ContainerPort = 8443
+		Expect(reconciler.Update(ctx, app)).To(Succeed())
+		reconcileApp()
+
+		policy := &networkingv1.NetworkPolicy{}
+		Expect(reconciler.Get(ctx, key, policy)).To(Succeed())
+		Expect(policy.Spec.Ingress[0].From).To(BeEmpty())
+		Expect(policy.Spec.Ingress[0].Ports[0].Port).To(HaveValue(Equal(intstr.FromInt32(8443))))
+	})
+
+	It("should delete the NetworkPolicy once networkPolicy is removed", func() {
+		reconcileApp()
+		Expect(reconciler.Get(ctx, key, &networkingv1.NetworkPolicy{})).To(Succeed())
+
+		app.Spec.NetworkPolicy = nil
+		Expect(reconciler.Update(ctx, app)).To(Succeed())
+		reconcileApp()
+
+		err := reconciler.Get(ctx, key, &networkingv1.NetworkPolicy{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,98 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1alpha1 "github.com/gxanlvxgx/simple-app-operator/api/v1"
+)
+
+// nodeCountResync is how often an app with spec.replicasFromNodeCount is reconciled again
+// to follow nodes joining and leaving the cluster.
+const nodeCountResync = time.Minute
+
+// nodeCount returns the number of nodes the app's pods can be scheduled on: ready, not cordoned,
+// matching spec.nodeSelector and spec.nodeArch and without NoSchedule/NoExecute taints the app doesn't tolerate.
+func (r *SimpleAppReconciler) nodeCount(ctx context.Context, cr *appsv1alpha1.SimpleApp) (int32, error) {
+	var nodes corev1.NodeList
+	if err := r.List(ctx, &nodes, client.MatchingLabels(nodeSelector(cr))); err != nil {
+		return 0, err
+	}
+	var count int32
+	for i := range nodes.Items {
+		if schedulable(&nodes.Items[i], cr.Spec.Tolerations) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// schedulable reports whether pods with the given tolerations can be placed on the node.
+func schedulable(node *corev1.Node, tolerations []corev1.Toleration) bool {
+	if node.Spec.Unschedulable {
+		return false
+	}
+	ready := false
+	for _, c := range node.Status.Conditions {
+		if c.Type == corev1.NodeReady {
+			ready = c.Status == corev1.ConditionTrue
+		}
+	}
+	if !ready {
+		return false
+	}
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		tolerated := false
+		for j := range tolerations {
+			if tolerations[j].ToleratesTaint(taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return false
+		}
+	}
+	return true
+}
+
+// requeueAfter returns the interval after which a reconciled app is reconciled again: the resync
+// period plus up to ResyncJitter of it, shortened to nodeCountResync for apps following the node count
+// and to restartCountResync for apps whose containers restarted.
+func (r *SimpleAppReconciler) requeueAfter(cr *appsv1alpha1.SimpleApp) time.Duration {
+	if cr.Spec.ReplicasFromNodeCount && (r.ResyncPeriod == 0 || r.ResyncPeriod > nodeCountResync) {
+		return nodeCountResync
+	}
+	if cr.Status.TotalRestarts > 0 && (r.ResyncPeriod == 0 || r.ResyncPeriod > restartCountResync) {
+		return restartCountResync
+	}
+	// wait.Jitter treats a factor of zero as 1
+	if r.ResyncPeriod == 0 || r.ResyncJitter <= 0 {
+		return r.ResyncPeriod
+	}
+	return wait.Jitter(r.ResyncPeriod, r.ResyncJitter)
+}
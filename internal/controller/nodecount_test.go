@@ -0,0 +1,120 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	k8sappsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appsv1 "github.com/gxanlvxgx/simple-app-operator/api/v1"
+)
+
+var _ = Describe("Replicas from the node count", func() {
+	var (
+		reconciler *SimpleAppReconciler
+		key        = client.ObjectKey{Name: "cache", Namespace: "default"}
+	)
+
+	node := func(name string, ready bool, mutate func(*corev1.Node)) *corev1.Node {
+		status := corev1.ConditionFalse
+		if ready {
+			status = corev1.ConditionTrue
+		}
+		n := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"pool": "cache"}},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}},
+			},
+		}
+		if mutate != nil {
+			mutate(n)
+		}
+		return n
+	}
+
+	reconcileReplicas := func() int32 {
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		ExpectWithOffset(1, result.RequeueAfter).To(Equal(nodeCountResync))
+		deployment := &k8sappsv1.Deployment{}
+		ExpectWithOffset(1, reconciler.Get(ctx, key, deployment)).To(Succeed())
+		return *deployment.Spec.Replicas
+	}
+
+	BeforeEach(func() {
+		controlPlane := func(n *corev1.Node) {
+			n.Spec.Taints = []corev1.Taint{{Key: "node-role.kubernetes.io/control-plane", Effect: corev1.TaintEffectNoSchedule}}
+		}
+		reconciler = &SimpleAppReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).
+				WithStatusSubresource(&appsv1.SimpleApp{}).
+				WithObjects(
+					node("worker-1", true, nil),
+					node("worker-2", true, nil),
+					node("cordoned", true, func(n *corev1.Node) { n.Spec.Unschedulable = true }),
+					node("not-ready", false, nil),
+					node("control-plane", true, controlPlane),
+					node("soft-tainted", true, func(n *corev1.Node) {
+						n.Spec.Taints = []corev1.Taint{{Key: "spot", Effect: corev1.TaintEffectPreferNoSchedule}}
+					}),
+				).Build(),
+			Scheme:   scheme.Scheme,
+			Recorder: record.NewFakeRecorder(100),
+		}
+		Expect(reconciler.Create(ctx, &appsv1.SimpleApp{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+			Spec: appsv1.SimpleAppSpec{
+				Image:                 "memcached:1.6",
+				Replicas:              ptr.To[int32](1),
+				ContainerPort:         11211,
+				ReplicasFromNodeCount: true,
+			},
+		})).To(Succeed())
+	})
+
+	It("should run one replica per schedulable node", func() {
+		Expect(reconcileReplicas()).To(Equal(int32(3)))
+
+		By("adding a node")
+		Expect(reconciler.Create(ctx, node("worker-3", true, nil))).To(Succeed())
+		Expect(reconcileReplicas()).To(Equal(int32(4)))
+	})
+
+	It("should count the nodes the app tolerates and selects", func() {
+		app := &appsv1.SimpleApp{}
+		Expect(reconciler.Get(ctx, key, app)).To(Succeed())
+		app.Spec.Tolerations = []corev1.Toleration{{Key: "node-role.kubernetes.io/control-plane", Operator: corev1.TolerationOpExists}}
+		Expect(reconciler.Update(ctx, app)).To(Succeed())
+		Expect(reconcileReplicas()).To(Equal(int32(4)))
+
+		By("restricting the app to a node pool")
+		Expect(reconciler.Get(ctx, key, app)).To(Succeed())
+		app.Spec.NodeSelector = map[string]string{"pool": "other"}
+		Expect(reconciler.Update(ctx, app)).To(Succeed())
+		Expect(reconcileReplicas()).To(Equal(int32(0)))
+	})
+})
@@ -0,0 +1,132 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	appsv1alpha1 "github.com/gxanlvxgx/simple-app-operator/api/v1"
+)
+
+// Notifier posts rollout notifications as Slack-compatible JSON to a webhook URL.
+type Notifier struct {
+	// URL is the operator-wide target. SimpleApps can override it with NotificationURLAnnotation.
+	URL string
+	// Events lists the notifications sent by default; all of them when empty.
+	// SimpleApps can override it with NotificationEventsAnnotation.
+	Events []string
+	// MinInterval is the minimum time between two notifications for the same SimpleApp.
+	MinInterval time.Duration
+	// HTTPClient sends the requests. Defaults to a client with a 10s timeout.
+	HTTPClient *http.Client
+
+	mu       sync.Mutex
+	lastSent map[types.NamespacedName]time.Time
+}
+
+// notificationPayload is understood by Slack incoming webhooks (which only read "text")
+// and carries structured fields for other receivers.
+type notificationPayload struct {
+	Text      string `json:"text"`
+	Event     string `json:"event"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// Notify posts event for cr unless the app opted out of it, no target is configured,
+// or a notification for the same app was sent less than MinInterval ago.
+// It reports whether a notification was posted.
+func (n *Notifier) Notify(ctx context.Context, cr *appsv1alpha1.SimpleApp, event, message string) (bool, error) {
+	url := n.URL
+	if v := cr.Annotations[appsv1alpha1.NotificationURLAnnotation]; v != "" {
+		url = v
+	}
+	if url == "" || !n.wants(cr, event) || !n.reserve(types.NamespacedName{Namespace: cr.Namespace, Name: cr.Name}) {
+		return false, nil
+	}
+
+	body, err := json.Marshal(notificationPayload{
+		Text:      fmt.Sprintf("SimpleApp %s/%s is %s: %s", cr.Namespace, cr.Name, event, message),
+		Event:     event,
+		Namespace: cr.Namespace,
+		Name:      cr.Name,
+	})
+	if err != nil {
+		return false, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	httpClient := n.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode >= 300 {
+		return false, fmt.Errorf("notification webhook returned %s", resp.Status)
+	}
+	return true, nil
+}
+
+// wants reports whether event is enabled for cr.
+func (n *Notifier) wants(cr *appsv1alpha1.SimpleApp, event string) bool {
+	events := n.Events
+	if v, ok := cr.Annotations[appsv1alpha1.NotificationEventsAnnotation]; ok {
+		events = strings.Split(v, ",")
+	} else if len(events) == 0 {
+		return true
+	}
+	for _, e := range events {
+		if strings.TrimSpace(e) == event {
+			return true
+		}
+	}
+	return false
+}
+
+// reserve records a notification attempt for key, returning false while it is throttled.
+// Failed attempts count too, so an unreachable receiver isn't hammered.
+func (n *Notifier) reserve(key types.NamespacedName) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	now := time.Now()
+	if last, ok := n.lastSent[key]; ok && now.Sub(last) < n.MinInterval {
+		return false
+	}
+	if n.lastSent == nil {
+		n.lastSent = map[types.NamespacedName]time.Time{}
+	}
+	n.lastSent[key] = now
+	return true
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appsv1 "github.com/gxanlvxgx/simple-app-operator/api/v1"
+)
+
+// notificationReceiver starts a local webhook receiver and returns the payloads it gets.
+func notificationReceiver(status int) (*httptest.Server, chan notificationPayload) {
+	received := make(chan notificationPayload, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer GinkgoRecover()
+		var payload notificationPayload
+		Expect(json.NewDecoder(r.Body).Decode(&payload)).To(Succeed())
+		received <- payload
+		w.WriteHeader(status)
+	}))
+	DeferCleanup(server.Close)
+	return server, received
+}
+
+var _ = Describe("Notifier", func() {
+	var app *appsv1.SimpleApp
+
+	BeforeEach(func() {
+		app = &appsv1.SimpleApp{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"}}
+	})
+
+	It("posts a Slack-compatible payload", func() {
+		server, received := notificationReceiver(http.StatusOK)
+		notifier := &Notifier{URL: server.URL}
+
+		sent, err := notifier.Notify(ctx, app, appsv1.NotificationReady, "2/2 replicas ready")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sent).To(BeTrue())
+
+		var payload notificationPayload
+		Expect(received).To(Receive(&payload))
+		Expect(payload.Text).To(Equal("SimpleApp shop/web is Ready: 2/2 replicas ready"))
+		Expect(payload.Event).To(Equal(appsv1.NotificationReady))
+	})
+
+	It("throttles notifications for the same app", func() {
+		server, received := notificationReceiver(http.StatusOK)
+		notifier := &Notifier{URL: server.URL, MinInterval: time.Hour}
+
+		Expect(notifier.Notify(ctx, app, appsv1.NotificationReady, "")).To(BeTrue())
+		Expect(notifier.Notify(ctx, app, appsv1.NotificationDegraded, "")).To(BeFalse())
+		Expect(received).To(HaveLen(1))
+
+		By("not throttling other apps")
+		other := app.DeepCopy()
+		other.Name = "api"
+		Expect(notifier.Notify(ctx, other, appsv1.NotificationDegraded, "")).To(BeTrue())
+	})
+
+	It("honours the per-app target and events", func() {
+		server, received := notificationReceiver(http.StatusOK)
+		notifier := &Notifier{Events: []string{appsv1.NotificationReady, appsv1.NotificationDegraded}}
+
+		By("skipping apps without a target")
+		Expect(notifier.Notify(ctx, app, appsv1.NotificationReady, "")).To(BeFalse())
+
+		app.Annotations = map[string]string{
+			appsv1.NotificationURLAnnotation:    server.URL,
+			appsv1.NotificationEventsAnnotation: "Degraded",
+		}
+		Expect(notifier.Notify(ctx, app, appsv1.NotificationReady, "")).To(BeFalse())
+		Expect(notifier.Notify(ctx, app, appsv1.NotificationDegraded, "")).To(BeTrue())
+		Expect(received).To(HaveLen(1))
+	})
+
+	It("reports receiver errors", func() {
+		server, _ := notificationReceiver(http.StatusInternalServerError)
+		notifier := &Notifier{URL: server.URL}
+
+		_, err := notifier.Notify(ctx, app, appsv1.NotificationReady, "")
+		Expect(err).To(MatchError(ContainSubstring("500")))
+	})
+})
@@ -0,0 +1,142 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	appsv1alpha1 "github.com/gxanlvxgx/simple-app-operator/api/v1"
+	"github.com/gxanlvxgx/simple-app-operator/internal/metrics"
+)
+
+// preDeleteJobSuffix is appended to the child name to name the pre-delete Job.
+const preDeleteJobSuffix = "-pre-delete"
+
+// ensurePreDeleteFinalizer adds the pre-delete finalizer while spec.preDeleteJob is set
+// and removes it once it isn't.
+func (r *SimpleAppReconciler) ensurePreDeleteFinalizer(ctx context.Context, cr *appsv1alpha1.SimpleApp) error {
+	var changed bool
+	if cr.Spec.PreDeleteJob != nil {
+		changed = controllerutil.AddFinalizer(cr, appsv1alpha1.PreDeleteFinalizer)
+	} else {
+		changed = controllerutil.RemoveFinalizer(cr, appsv1alpha1.PreDeleteFinalizer)
+	}
+	if !changed {
+		return nil
+	}
+	return r.Update(ctx, cr)
+}
+
+// finalize runs the pre-delete Job of a SimpleApp being deleted and releases the finalizer
+// once the Job succeeded. A failed Job keeps the finalizer, so the deletion waits until the
+// Job is deleted (and thereby retried) or the finalizer is removed by hand.
+func (r *SimpleAppReconciler) finalize(ctx context.Context, cr *appsv1alpha1.SimpleApp, name string) error {
+	if !controllerutil.ContainsFinalizer(cr, appsv1alpha1.PreDeleteFinalizer) {
+		return nil
+	}
+	if cr.Spec.PreDeleteJob == nil {
+		controllerutil.RemoveFinalizer(cr, appsv1alpha1.PreDeleteFinalizer)
+		return r.Update(ctx, cr)
+	}
+	log := logf.FromContext(ctx).WithValues("Job", name+preDeleteJobSuffix, "Namespace", cr.Namespace)
+
+	var job batchv1.Job
+	err := r.getChild(ctx, client.ObjectKey{Name: name + preDeleteJobSuffix, Namespace: cr.Namespace}, &job)
+	if client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	if err != nil {
+		desired := r.preDeleteJob(cr, name)
+		if err := ctrl.SetControllerReference(cr, desired, r.Scheme); err != nil {
+			return err
+		}
+		log.V(1).Info("Creating pre-delete Job")
+		if err := r.Create(ctx, desired); err != nil {
+			return err
+		}
+		metrics.RecordChildOperation("Job", metrics.OperationCreate)
+		r.Recorder.Eventf(cr, corev1.EventTypeNormal, "PreDeleteJobStarted", "Started pre-delete Job %s", desired.Name)
+		return nil
+	}
+
+	switch {
+	case jobFinished(&job, batchv1.JobComplete):
+		log.V(1).Info("Pre-delete Job succeeded, removing the finalizer")
+		r.Recorder.Eventf(cr, corev1.EventTypeNormal, "PreDeleteJobSucceeded", "Pre-delete Job %s succeeded", job.Name)
+		controllerutil.RemoveFinalizer(cr, appsv1alpha1.PreDeleteFinalizer)
+		return r.Update(ctx, cr)
+	case jobFinished(&job, batchv1.JobFailed):
+		r.Recorder.Eventf(cr, corev1.EventTypeWarning, "PreDeleteJobFailed",
+			"Pre-delete Job %s failed, so the SimpleApp is kept; delete the Job to retry it or remove the %s finalizer to skip it",
+			job.Name, appsv1alpha1.PreDeleteFinalizer)
+	default:
+		// The Job is owned by the SimpleApp, so its completion triggers another reconcile
+		log.V(1).Info("Waiting for the pre-delete Job")
+	}
+	return nil
+}
+
+// preDeleteJob builds the pre-delete Job. It runs with the app's service account, secrets and
+// security contexts, so it can reach the same systems as the app, and carries the labels of the
+// other children.
+func (r *SimpleAppReconciler) preDeleteJob(cr *appsv1alpha1.SimpleApp, name string) *batchv1.Job {
+	image := cr.Spec.PreDeleteJob.Image
+	if image == "" {
+		image = cr.Spec.Image
+	}
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + preDeleteJobSuffix,
+			Namespace: cr.Namespace,
+			Labels:    r.childLabels(cr),
+		},
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy:      corev1.RestartPolicyNever,
+					ServiceAccountName: cr.Spec.ServiceAccountName,
+					SecurityContext:    podSecurityContext(cr),
+					Containers: []corev1.Container{{
+						Name:            "pre-delete",
+						Image:           image,
+						Command:         cr.Spec.PreDeleteJob.Command,
+						EnvFrom:         secretEnvFrom(cr),
+						SecurityContext: cr.Spec.SecurityContext,
+					}},
+				},
+			},
+		},
+	}
+}
+
+// jobFinished reports whether the Job has the given terminal condition.
+func jobFinished(job *batchv1.Job, conditionType batchv1.JobConditionType) bool {
+	for _, c := range job.Status.Conditions {
+		if c.Type == conditionType && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appsv1 "github.com/gxanlvxgx/simple-app-operator/api/v1"
+)
+
+var _ = Describe("Pre-delete Job", func() {
+	var (
+		reconciler *SimpleAppReconciler
+		recorder   *record.FakeRecorder
+		app        *appsv1.SimpleApp
+		key        = client.ObjectKey{Name: "web", Namespace: "default"}
+		jobKey     = client.ObjectKey{Name: "web-pre-delete", Namespace: "default"}
+	)
+
+	reconcileApp := func() {
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	}
+
+	// deleteApp deletes the SimpleApp and reconciles until the pre-delete Job was started.
+	deleteApp := func() *batchv1.Job {
+		Expect(reconciler.Get(ctx, key, app)).To(Succeed())
+		Expect(reconciler.Delete(ctx, app)).To(Succeed())
+		reconcileApp()
+
+		Expect(reconciler.Get(ctx, key, app)).To(Succeed())
+		Expect(app.DeletionTimestamp).NotTo(BeNil())
+		Expect(recorder.Events).To(Receive(ContainSubstring("PreDeleteJobStarted")))
+
+		job := &batchv1.Job{}
+		Expect(reconciler.Get(ctx, jobKey, job)).To(Succeed())
+		return job
+	}
+
+	finishJob := func(job *batchv1.Job, conditionType batchv1.JobConditionType) {
+		job.Status.Conditions = append(job.Status.Conditions, batchv1.JobCondition{
+			Type:   conditionType,
+			Status: corev1.ConditionTrue,
+		})
+		Expect(reconciler.Status().Update(ctx, job)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		recorder = record.NewFakeRecorder(100)
+		reconciler = &SimpleAppReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).
+				WithStatusSubresource(&appsv1.SimpleApp{}, &batchv1.Job{}).Build(),
+			Scheme:   scheme.Scheme,
+			Recorder: recorder,
+		}
+		app = &appsv1.SimpleApp{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: appsv1.SimpleAppSpec{
+				Image:         "registry.example.com/web:1.0",
+				ContainerPort: 8080,
+				Replicas:      ptr.To[int32](1),
+				PreDeleteJob:  &appsv1.PreDeleteJobSpec{Command: []string{"/bin/deregister", "--all"}},
+			},
+		}
+		Expect(reconciler.Create(ctx, app)).To(Succeed())
+		reconcileApp()
+		Expect(reconciler.Get(ctx, key, app)).To(Succeed())
+		Expect(app.Finalizers).To(ContainElement(appsv1.PreDeleteFinalizer))
+	})
+
+	It("runs the Job and releases the SimpleApp once it succeeded", func() {
+		job := deleteApp()
+		Expect(metav1.IsControlledBy(job, app)).To(BeTrue())
+		Expect(job.Labels).To(HaveKeyWithValue("app", "web"))
+		container := job.Spec.Template.Spec.Containers[0]
+		Expect(container.Image).To(Equal("registry.example.com/web:1.0"))
+		Expect(container.Command).To(Equal([]string{"/bin/deregister", "--all"}))
+		Expect(job.Spec.Template.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyNever))
+
+		By("waiting while the Job runs")
+		reconcileApp()
+		Expect(reconciler.Get(ctx, key, app)).To(Succeed())
+
+		By("completing the Job")
+		finishJob(job, batchv1.JobComplete)
+		reconcileApp()
+		Expect(errors.IsNotFound(reconciler.Get(ctx, key, app))).To(BeTrue())
+		Expect(recorder.Events).To(Receive(ContainSubstring("PreDeleteJobSucceeded")))
+	})
+
+	It("keeps the finalizer and warns when the Job failed", func() {
+		job := deleteApp()
+		finishJob(job, batchv1.JobFailed)
+		reconcileApp()
+
+		Expect(reconciler.Get(ctx, key, app)).To(Succeed())
+		Expect(app.Finalizers).To(ContainElement(appsv1.PreDeleteFinalizer))
+		Expect(recorder.Events).To(Receive(And(ContainSubstring("Warning"), ContainSubstring("PreDeleteJobFailed"))))
+
+		By("retrying after the failed Job was deleted")
+		Expect(reconciler.Delete(ctx, job)).To(Succeed())
+		reconcileApp()
+		Expect(reconciler.Get(ctx, jobKey, job)).To(Succeed())
+		Expect(recorder.Events).To(Receive(ContainSubstring("PreDeleteJobStarted")))
+	})
+
+	It("drops the finalizer when the pre-delete Job is removed from the spec", func() {
+		app.Spec.PreDeleteJob = nil
+		Expect(reconciler.Update(ctx, app)).To(Succeed())
+		reconcileApp()
+
+		Expect(reconciler.Get(ctx, key, app)).To(Succeed())
+		Expect(app.Finalizers).NotTo(ContainElement(appsv1.PreDeleteFinalizer))
+	})
+})
@@ -0,0 +1,101 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	appsv1alpha1 "github.com/gxanlvxgx/simple-app-operator/api/v1"
+)
+
+// rolledBackReason is the reason of the event emitted when spec.autoRollback reverts an image.
+const rolledBackReason = "RolledBack"
+
+// appImage returns the image of the app container: spec.image, or the last image that rolled out
+// while spec.autoRollback has reverted spec.image.
+func appImage(cr *appsv1alpha1.SimpleApp) string {
+	if cr.Spec.AutoRollback && cr.Status.FailedImage == cr.Spec.Image && cr.Status.LastGoodImage != "" {
+		return cr.Status.LastGoodImage
+	}
+	return cr.Spec.Image
+}
+
+// rolloutComplete reports whether every replica of the Deployment runs its current pod template
+// and is available. A Deployment scaled to zero never completes, since no pod tried the image.
+func rolloutComplete(cr *appsv1alpha1.SimpleApp, dep *appsv1.Deployment) bool {
+	replicas := desiredReplicas(cr, dep)
+	return replicas > 0 &&
+		dep.Status.ObservedGeneration >= dep.Generation &&
+		dep.Status.Replicas == dep.Status.UpdatedReplicas &&
+		dep.Status.UpdatedReplicas >= replicas &&
+		dep.Status.AvailableReplicas >= replicas &&
+		progressDeadlineExceeded(dep) == nil
+}
+
+// lastGoodImage returns the status.lastGoodImage to record: the running image once its rollout
+// completed, the previous one otherwise. An image set on the Deployment by this reconcile doesn't
+// count, since the Deployment status still describes the previous one.
+func lastGoodImage(cr *appsv1alpha1.SimpleApp, dep *appsv1.Deployment) string {
+	if image := runningImage(cr, dep); image == cr.Status.RunningImage && rolloutComplete(cr, dep) {
+		return image
+	}
+	return cr.Status.LastGoodImage
+}
+
+// failedImage returns the status.failedImage to keep: it only applies while spec.image still
+// names it and spec.autoRollback is set, so a new image or turning the option off rolls forward.
+func failedImage(cr *appsv1alpha1.SimpleApp) string {
+	if !cr.Spec.AutoRollback || cr.Status.FailedImage != cr.Spec.Image {
+		return ""
+	}
+	return cr.Status.FailedImage
+}
+
+// autoRollback reverts spec.image when spec.autoRollback is set and the rollout of the Deployment
+// exceeded its progress deadline: the image is recorded in status.failedImage of cr, which makes
+// appImage return status.lastGoodImage until spec.image changes. It runs before the Deployment is
+// updated and only changes cr in memory; Reconcile writes the status with the rest.
+func (r *SimpleAppReconciler) autoRollback(ctx context.Context, cr *appsv1alpha1.SimpleApp, name string) error {
+	image, good := cr.Spec.Image, cr.Status.LastGoodImage
+	if !cr.Spec.AutoRollback || good == "" || good == image || cr.Status.FailedImage == image ||
+		cr.Status.RunningImage != image {
+		return nil
+	}
+	var dep appsv1.Deployment
+	if err := r.Get(ctx, client.ObjectKey{Name: name, Namespace: cr.Namespace}, &dep); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	// The Progressing condition must be about the current pod template, set by an earlier reconcile
+	if runningImage(cr, &dep) != image || dep.Status.ObservedGeneration < dep.Generation {
+		return nil
+	}
+	stuck := progressDeadlineExceeded(&dep)
+	if stuck == nil {
+		return nil
+	}
+
+	logf.FromContext(ctx).Info("Rolling back a stuck rollout", "Deployment", dep.Name, "Image", image, "LastGoodImage", good)
+	cr.Status.FailedImage = image
+	r.Recorder.Eventf(cr, corev1.EventTypeWarning, rolledBackReason,
+		"Rollout of image %s made no progress within its deadline (%s), rolling back to %s", image, stuck.Message, good)
+	return nil
+}
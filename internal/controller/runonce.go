@@ -0,0 +1,221 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	appsv1alpha1 "github.com/gxanlvxgx/simple-app-operator/api/v1"
+	"github.com/gxanlvxgx/simple-app-operator/internal/metrics"
+)
+
+// reconcileRunOnce reconciles a SimpleApp with spec.runOnce: it removes the Deployments, Service,
+// Ingress, PodDisruptionBudget, HorizontalPodAutoscaler and ServiceMonitor of a previously
+// long-running app, runs the app as a Job and reports the Job's progress in status.
+func (r *SimpleAppReconciler) reconcileRunOnce(ctx context.Context, cr *appsv1alpha1.SimpleApp, name string) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+	// ensureJob sets the ReferencesMissing condition in memory, so keep the status as read
+	observedStatus := cr.Status.DeepCopy()
+
+	var deployment appsv1.Deployment
+	err := r.Get(ctx, client.ObjectKey{Name: name, Namespace: cr.Namespace}, &deployment)
+	if client.IgnoreNotFound(err) != nil {
+		return ctrl.Result{}, err
+	}
+	if err == nil && metav1.IsControlledBy(&deployment, cr) {
+		log.V(1).Info("Deleting Deployment", "Reason", "runOnce is set", "Deployment", name, "Namespace", cr.Namespace)
+		if err := r.Delete(ctx, &deployment); client.IgnoreNotFound(err) != nil {
+			return ctrl.Result{}, err
+		}
+		metrics.RecordChildOperation("Deployment", metrics.OperationDelete)
+	}
+	// Neither gets created for run-once apps, so this only removes leftovers
+	if _, err := r.ensureService(ctx, cr, name); err != nil {
+		return ctrl.Result{}, err
+	}
+	if _, err := r.ensureIngress(ctx, cr, name); err != nil {
+		return ctrl.Result{}, err
+	}
+	// A Job has no replicas to split, protect, scale or scrape, so these children are removed as if
+	// their specs were unset, as the CRD requires them to be; the canary Deployment goes with the
+	// renamed children
+	longRunning := cr.DeepCopy()
+	longRunning.Spec.Canary = nil
+	longRunning.Spec.PodDisruptionBudget = nil
+	longRunning.Spec.Autoscaling = nil
+	longRunning.Spec.Metrics = nil
+	if err := r.ensurePDB(ctx, longRunning, name, 0); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.ensureHPA(ctx, longRunning, name); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.ensureServiceMonitor(ctx, longRunning, name); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.pruneRenamedChildren(ctx, longRunning, name); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.ensureNetworkPolicy(ctx, cr, name); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.ensurePVC(ctx, cr, name); err != nil {
+		return ctrl.Result{}, err
+	}
+	job, err := r.ensureJob(ctx, cr, name)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	complete := completeCondition(cr, job)
+	previous := meta.FindStatusCondition(cr.Status.Conditions, appsv1alpha1.ConditionComplete)
+	if previous == nil || previous.Reason != complete.Reason {
+		switch complete.Reason {
+		case jobSucceededReason:
+			r.Recorder.Event(cr, corev1.EventTypeNormal, jobSucceededReason, complete.Message)
+		case jobFailedReason:
+			r.Recorder.Event(cr, corev1.EventTypeWarning, jobFailedReason, complete.Message)
+		}
+	}
+
+	status := cr.Status.DeepCopy()
+	status.ReadyReplicas = ptr.Deref(job.Status.Ready, 0)
+	status.UnavailableReplicas = 0
+	status.UpdatedReplicas = 0
+	status.Message = ""
+	status.ServiceStatus = ""
+	if complete.Reason == jobFailedReason {
+		status.Message = complete.Message
+		status.ServiceStatus = appsv1alpha1.ServiceStatusFailed
+	}
+	status.ServiceDNS = ""
+	status.ServiceEndpoint = nil
+	status.RunningImage = ""
+	if i := containerIndex(job.Spec.Template.Spec.Containers, containerName(cr)); i >= 0 {
+		status.RunningImage = job.Spec.Template.Spec.Containers[i].Image
+	}
+	status.Summary = fmt.Sprintf("job %s, image %s", jobPhases[complete.Reason], cr.Spec.Image)
+	// The Deployment conditions don't apply to a Job
+	meta.RemoveStatusCondition(&status.Conditions, appsv1alpha1.ConditionReady)
+	meta.RemoveStatusCondition(&status.Conditions, appsv1alpha1.ConditionSelectorCollision)
+	meta.SetStatusCondition(&status.Conditions, complete)
+	status.ObservedGeneration = cr.Generation
+	if !equality.Semantic.DeepEqual(*status, *observedStatus) {
+		status.LastReconcileTime = ptr.To(metav1.Now())
+		log.V(1).Info("Updating SimpleApp status", "Namespace", cr.Namespace, "Name", cr.Name, "Reason", complete.Reason)
+		cr.Status = *status
+		if err := r.Status().Update(ctx, cr); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{RequeueAfter: r.requeueAfter(cr)}, nil
+}
+
+// ensureJob creates the Job of a run-once app. The pod template of a Job is immutable,
+// so an existing Job is returned as it is.
+func (r *SimpleAppReconciler) ensureJob(ctx context.Context, cr *appsv1alpha1.SimpleApp, name string) (*batchv1.Job, error) {
+	// Missing ConfigMaps/Secrets don't block the Job: its pod waits until they appear
+	if err := r.warnMissingReferences(ctx, cr); err != nil {
+		return nil, err
+	}
+
+	var existing batchv1.Job
+	err := r.getChild(ctx, client.ObjectKey{Name: name, Namespace: cr.Namespace}, &existing)
+	if client.IgnoreNotFound(err) != nil {
+		return nil, err
+	}
+	if err == nil {
+		return &existing, nil
+	}
+	template := podTemplate(cr, name)
+	template.Spec.RestartPolicy = corev1.RestartPolicyNever
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: cr.Namespace,
+			Labels:    r.childLabels(cr),
+		},
+		Spec: batchv1.JobSpec{Template: template},
+	}
+	if err := ctrl.SetControllerReference(cr, job, r.Scheme); err != nil {
+		return nil, err
+	}
+	logf.FromContext(ctx).V(1).Info("Creating Job", "Job", name, "Namespace", cr.Namespace)
+	if err := r.Create(ctx, job); err != nil {
+		return nil, err
+	}
+	metrics.RecordChildOperation("Job", metrics.OperationCreate)
+	r.Recorder.Eventf(cr, corev1.EventTypeNormal, "JobStarted", "Started Job %s", job.Name)
+	return job, nil
+}
+
+// Reasons of the Complete condition.
+const (
+	jobRunningReason   = "JobRunning"
+	jobSucceededReason = "JobSucceeded"
+	jobFailedReason    = "JobFailed"
+)
+
+// jobPhases describes the Complete condition reasons in status.summary.
+var jobPhases = map[string]string{
+	jobRunningReason:   "running",
+	jobSucceededReason: "succeeded",
+	jobFailedReason:    "failed",
+}
+
+// completeCondition computes the Complete condition from the terminal conditions of the Job.
+func completeCondition(cr *appsv1alpha1.SimpleApp, job *batchv1.Job) metav1.Condition {
+	condition := metav1.Condition{
+		Type:               appsv1alpha1.ConditionComplete,
+		Status:             metav1.ConditionFalse,
+		Reason:             jobRunningReason,
+		Message:            fmt.Sprintf("Job %s is running", job.Name),
+		ObservedGeneration: cr.Generation,
+	}
+	for _, c := range job.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batchv1.JobComplete:
+			condition.Status = metav1.ConditionTrue
+			condition.Reason = jobSucceededReason
+			condition.Message = fmt.Sprintf("Job %s succeeded", job.Name)
+		case batchv1.JobFailed:
+			condition.Reason = jobFailedReason
+			condition.Message = fmt.Sprintf("Job %s failed", job.Name)
+			if c.Message != "" {
+				condition.Message += ": " + c.Message
+			}
+		}
+	}
+	return condition
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	k8sappsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appsv1 "github.com/gxanlvxgx/simple-app-operator/api/v1"
+)
+
+var _ = Describe("Run-once apps", func() {
+	var (
+		reconciler *SimpleAppReconciler
+		recorder   *record.FakeRecorder
+		app        *appsv1.SimpleApp
+		key        = client.ObjectKey{Name: "migrate", Namespace: "default"}
+	)
+
+	reconcileApp := func() {
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		ExpectWithOffset(1, reconciler.Get(ctx, key, app)).To(Succeed())
+	}
+
+	finishJob := func(conditionType batchv1.JobConditionType, message string) {
+		job := &batchv1.Job{}
+		Expect(reconciler.Get(ctx, key, job)).To(Succeed())
+		job.Status.Conditions = append(job.Status.Conditions, batchv1.JobCondition{
+			Type:    conditionType,
+			Status:  corev1.ConditionTrue,
+			Message: message,
+		})
+		Expect(reconciler.Status().Update(ctx, job)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		recorder = record.NewFakeRecorder(100)
+		reconciler = &SimpleAppReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).
+				WithStatusSubresource(&appsv1.SimpleApp{}, &batchv1.Job{}).Build(),
+			Scheme:   scheme.Scheme,
+			Recorder: recorder,
+		}
+		app = &appsv1.SimpleApp{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+			Spec: appsv1.SimpleAppSpec{
+				Image:         "migrate/migrate:v4.17.0",
+				Replicas:      ptr.To[int32](1),
+				ContainerPort: 8080,
+				RunOnce:       true,
+			},
+		}
+		Expect(reconciler.Create(ctx, app)).To(Succeed())
+	})
+
+	It("should run the app as a Job and report its success", func() {
+		reconcileApp()
+		Expect(recorder.Events).To(Receive(ContainSubstring("JobStarted")))
+
+		job := &batchv1.Job{}
+		Expect(reconciler.Get(ctx, key, job)).To(Succeed())
+		Expect(metav1.IsControlledBy(job, app)).To(BeTrue())
+		Expect(job.Spec.Template.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyNever))
+		Expect(job.Spec.Template.Spec.Containers[0].Image).To(Equal("migrate/migrate:v4.17.0"))
+		Expect(errors.IsNotFound(reconciler.Get(ctx, key, &k8sappsv1.Deployment{}))).To(BeTrue())
+		Expect(errors.IsNotFound(reconciler.Get(ctx, key, &corev1.Service{}))).To(BeTrue())
+
+		complete := meta.FindStatusCondition(app.Status.Conditions, appsv1.ConditionComplete)
+		Expect(complete).NotTo(BeNil())
+		Expect(complete.Status).To(Equal(metav1.ConditionFalse))
+		Expect(complete.Reason).To(Equal("JobRunning"))
+		Expect(app.Status.Summary).To(Equal("job running, image migrate/migrate:v4.17.0"))
+		Expect(app.Status.RunningImage).To(Equal("migrate/migrate:v4.17.0"))
+
+		By("completing the Job")
+		finishJob(batchv1.JobComplete, "")
+		reconcileApp()
+		Expect(meta.IsStatusConditionTrue(app.Status.Conditions, appsv1.ConditionComplete)).To(BeTrue())
+		Expect(app.Status.Summary).To(Equal("job succeeded, image migrate/migrate:v4.17.0"))
+		Expect(recorder.Events).To(Receive(ContainSubstring("JobSucceeded")))
+
+		By("reconciling the finished Job again")
+		reconcileApp()
+		Expect(recorder.Events).NotTo(Receive())
+	})
+
+	It("should report a failed Job", func() {
+		reconcileApp()
+		finishJob(batchv1.JobFailed, "Job has reached the specified backoff limit")
+		reconcileApp()
+
+		complete := meta.FindStatusCondition(app.Status.Conditions, appsv1.ConditionComplete)
+		Expect(complete.Status).To(Equal(metav1.ConditionFalse))
+		Expect(complete.Reason).To(Equal("JobFailed"))
+		Expect(app.Status.ServiceStatus).To(Equal(appsv1.ServiceStatusFailed))
+		Expect(app.Status.Message).To(ContainSubstring("backoff limit"))
+		Expect(recorder.Events).To(Receive(ContainSubstring("JobStarted")))
+		Expect(recorder.Events).To(Receive(ContainSubstring("JobFailed")))
+	})
+
+	It("should replace the Deployment and Service of a long-running app", func() {
+		app.Spec.RunOnce = false
+		Expect(reconciler.Update(ctx, app)).To(Succeed())
+		reconcileApp()
+		Expect(reconciler.Get(ctx, key, &k8sappsv1.Deployment{})).To(Succeed())
+		Expect(reconciler.Get(ctx, key, &corev1.Service{})).To(Succeed())
+
+		app.Spec.RunOnce = true
+		Expect(reconciler.Update(ctx, app)).To(Succeed())
+		reconcileApp()
+		Expect(errors.IsNotFound(reconciler.Get(ctx, key, &k8sappsv1.Deployment{}))).To(BeTrue())
+		Expect(errors.IsNotFound(reconciler.Get(ctx, key, &corev1.Service{}))).To(BeTrue())
+		Expect(reconciler.Get(ctx, key, &batchv1.Job{})).To(Succeed())
+		Expect(meta.FindStatusCondition(app.Status.Conditions, appsv1.ConditionReady)).To(BeNil())
+	})
+
+	It("should remove the canary and PodDisruptionBudget of a long-running app", func() {
+		app.Spec.RunOnce = false
+		app.Spec.Canary = &appsv1.CanarySpec{Image: "migrate/migrate:v4.18.0", Weight: 50}
+		app.Spec.PodDisruptionBudget = &appsv1.PodDisruptionBudgetSpec{MinAvailable: ptr.To(intstr.FromInt32(1))}
+		Expect(reconciler.Update(ctx, app)).To(Succeed())
+		reconcileApp()
+		canaryKey := client.ObjectKey{Name: key.Name + "-canary", Namespace: key.Namespace}
+		Expect(reconciler.Get(ctx, canaryKey, &k8sappsv1.Deployment{})).To(Succeed())
+		Expect(reconciler.Get(ctx, key, &policyv1.PodDisruptionBudget{})).To(Succeed())
+
+		By("switching to runOnce, which the CRD doesn't allow next to either")
+		app.Spec.RunOnce = true
+		app.Spec.Canary = nil
+		app.Spec.PodDisruptionBudget = nil
+		Expect(reconciler.Update(ctx, app)).To(Succeed())
+		reconcileApp()
+		Expect(errors.IsNotFound(reconciler.Get(ctx, key, &k8sappsv1.Deployment{}))).To(BeTrue())
+		Expect(errors.IsNotFound(reconciler.Get(ctx, canaryKey, &k8sappsv1.Deployment{}))).To(BeTrue())
+		Expect(errors.IsNotFound(reconciler.Get(ctx, key, &policyv1.PodDisruptionBudget{}))).To(BeTrue())
+		Expect(reconciler.Get(ctx, key, &batchv1.Job{})).To(Succeed())
+	})
+
+	It("should remove the HorizontalPodAutoscaler of a long-running app", func() {
+		app.Spec.RunOnce = false
+		app.Spec.Autoscaling = &appsv1.AutoscalingSpec{MaxReplicas: 3}
+		Expect(reconciler.Update(ctx, app)).To(Succeed())
+		reconcileApp()
+		Expect(reconciler.Get(ctx, key, &autoscalingv2.HorizontalPodAutoscaler{})).To(Succeed())
+
+		app.Spec.RunOnce = true
+		app.Spec.Autoscaling = nil
+		Expect(reconciler.Update(ctx, app)).To(Succeed())
+		reconcileApp()
+		Expect(errors.IsNotFound(reconciler.Get(ctx, key, &autoscalingv2.HorizontalPodAutoscaler{}))).To(BeTrue())
+		Expect(reconciler.Get(ctx, key, &batchv1.Job{})).To(Succeed())
+	})
+})
@@ -0,0 +1,253 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	appsv1alpha1 "github.com/gxanlvxgx/simple-app-operator/api/v1"
+	"github.com/gxanlvxgx/simple-app-operator/internal/metrics"
+)
+
+// The Prometheus Operator API is optional, so ServiceMonitors are handled as unstructured objects
+// instead of pulling in its Go module.
+var serviceMonitorGVK = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"}
+
+// defaultMetricsPortName matches the CRD default of spec.metrics.portName.
+const defaultMetricsPortName = "metrics"
+
+// metricsPortName returns the name of the metrics port on the container and the Service.
+func metricsPortName(cr *appsv1alpha1.SimpleApp) string {
+	if cr.Spec.Metrics.PortName == "" {
+		return defaultMetricsPortName
+	}
+	return cr.Spec.Metrics.PortName
+}
+
+// adminPortName names spec.adminPort on the container and the Service.
+const adminPortName = "admin"
+
+// containerPorts lists the ports of the application container. The metrics port is only
+// added when it differs from the main one.
+func containerPorts(cr *appsv1alpha1.SimpleApp) []corev1.ContainerPort {
+	ports := []corev1.ContainerPort{{
+		Name:          cr.Spec.TargetPortName,
+		ContainerPort: cr.Spec.ContainerPort,
+		Protocol:      corev1.ProtocolTCP,
+	}}
+	if cr.Spec.Metrics != nil && cr.Spec.Metrics.Port != cr.Spec.ContainerPort {
+		ports = append(ports, corev1.ContainerPort{
+			Name:          metricsPortName(cr),
+			ContainerPort: cr.Spec.Metrics.Port,
+			Protocol:      corev1.ProtocolTCP,
+		})
+	}
+	if cr.Spec.AdminPort != nil {
+		ports = append(ports, corev1.ContainerPort{
+			Name:          adminPortName,
+			ContainerPort: *cr.Spec.AdminPort,
+			Protocol:      corev1.ProtocolTCP,
+		})
+	}
+	return ports
+}
+
+// servicePorts lists the ports of the Service. Ports are only named when metrics are enabled,
+// since ServiceMonitors refer to the metrics port by name, for headless Services, whose
+// named ports get SRV records, or when there are several, as the API requires.
+func (r *SimpleAppReconciler) servicePorts(cr *appsv1alpha1.SimpleApp) []corev1.ServicePort {
+	main := corev1.ServicePort{
+		Port:       r.servicePort(cr),
+		TargetPort: targetPort(cr),
+	}
+	var ports []corev1.ServicePort
+	switch {
+	case cr.Spec.Metrics == nil:
+		if cr.Spec.Headless || cr.Spec.AdminPort != nil {
+			main.Name = "http"
+		}
+		ports = []corev1.ServicePort{main}
+	case cr.Spec.Metrics.Port == cr.Spec.ContainerPort:
+		main.Name = metricsPortName(cr)
+		ports = []corev1.ServicePort{main}
+	default:
+		main.Name = "http"
+		ports = []corev1.ServicePort{main, {
+			Name:       metricsPortName(cr),
+			Port:       cr.Spec.Metrics.Port,
+			TargetPort: intstr.FromInt(int(cr.Spec.Metrics.Port)),
+		}}
+	}
+	if cr.Spec.AdminPort != nil {
+		ports = append(ports, corev1.ServicePort{
+			Name:       adminPortName,
+			Port:       *cr.Spec.AdminPort,
+			TargetPort: intstr.FromInt(int(*cr.Spec.AdminPort)),
+		})
+	}
+	return ports
+}
+
+// targetPort returns the container port the Service routes to: by name when spec.targetPortName
+// is set, otherwise by number.
+func targetPort(cr *appsv1alpha1.SimpleApp) intstr.IntOrString {
+	if cr.Spec.TargetPortName != "" {
+		return intstr.FromString(cr.Spec.TargetPortName)
+	}
+	return intstr.FromInt(int(cr.Spec.ContainerPort))
+}
+
+// scrapeAnnotationKeys are the pod template annotations managed through spec.prometheusScrape.
+var scrapeAnnotationKeys = []string{"prometheus.io/scrape", "prometheus.io/port", "prometheus.io/path"}
+
+// scrapeAnnotations returns the prometheus.io/* pod annotations for spec.prometheusScrape,
+// or nil when it is unset.
+func scrapeAnnotations(cr *appsv1alpha1.SimpleApp) map[string]string {
+	scrape := cr.Spec.PrometheusScrape
+	if scrape == nil {
+		return nil
+	}
+	port := scrape.Port
+	if port == 0 && cr.Spec.Metrics != nil {
+		port = cr.Spec.Metrics.Port
+	}
+	if port == 0 {
+		port = cr.Spec.ContainerPort
+	}
+	path := scrape.Path
+	if path == "" {
+		path = "/metrics"
+	}
+	return map[string]string{
+		"prometheus.io/scrape": "true",
+		"prometheus.io/port":   strconv.Itoa(int(port)),
+		"prometheus.io/path":   path,
+	}
+}
+
+// serviceMonitorsAvailable reports whether the ServiceMonitor CRD is installed.
+func serviceMonitorsAvailable(mapper meta.RESTMapper) (bool, error) {
+	_, err := mapper.RESTMapping(serviceMonitorGVK.GroupKind(), serviceMonitorGVK.Version)
+	if meta.IsNoMatchError(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// ensureServiceMonitor creates or updates the ServiceMonitor scraping the app's metrics port,
+// and deletes it once spec.metrics is removed. It does nothing on clusters without the CRD.
+func (r *SimpleAppReconciler) ensureServiceMonitor(ctx context.Context, cr *appsv1alpha1.SimpleApp, name string) error {
+	available, err := serviceMonitorsAvailable(r.RESTMapper())
+	if err != nil {
+		return err
+	}
+	if !available {
+		if cr.Spec.Metrics != nil {
+			logf.FromContext(ctx).V(1).Info("Skipping ServiceMonitor, the Prometheus Operator CRDs are not installed")
+		}
+		return nil
+	}
+
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(serviceMonitorGVK)
+	err = r.Get(ctx, client.ObjectKey{Name: name, Namespace: cr.Namespace}, existing)
+	if client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	found := err == nil
+
+	if cr.Spec.Metrics == nil {
+		if found && metav1.IsControlledBy(existing, cr) {
+			if err := r.Delete(ctx, existing); client.IgnoreNotFound(err) != nil {
+				return err
+			}
+			metrics.RecordChildOperation(serviceMonitorGVK.Kind, metrics.OperationDelete)
+		}
+		return nil
+	}
+
+	endpoint := map[string]any{"port": metricsPortName(cr)}
+	if cr.Spec.Metrics.Path != "" {
+		endpoint["path"] = cr.Spec.Metrics.Path
+	}
+	if cr.Spec.Metrics.Interval != "" {
+		endpoint["interval"] = cr.Spec.Metrics.Interval
+	}
+	// The Service carries the pod labels, so the pod selector selects it too
+	matchLabels := map[string]any{}
+	for k, v := range appLabels(cr) {
+		matchLabels[k] = v
+	}
+	spec := map[string]any{
+		"selector": map[string]any{
+			"matchLabels": matchLabels,
+		},
+		"endpoints": []any{endpoint},
+	}
+	labels := r.childLabels(cr)
+
+	if !found {
+		if found, err = r.childExists(ctx, client.ObjectKey{Name: name, Namespace: cr.Namespace}, existing); err != nil {
+			return err
+		}
+	}
+	if !found {
+		sm := &unstructured.Unstructured{}
+		sm.SetGroupVersionKind(serviceMonitorGVK)
+		sm.SetName(name)
+		sm.SetNamespace(cr.Namespace)
+		sm.SetLabels(labels)
+		sm.Object["spec"] = spec
+		if err := ctrl.SetControllerReference(cr, sm, r.Scheme); err != nil {
+			return err
+		}
+		if err := r.Create(ctx, sm); err != nil {
+			return err
+		}
+		metrics.RecordChildOperation(serviceMonitorGVK.Kind, metrics.OperationCreate)
+		return nil
+	}
+
+	if !equality.Semantic.DeepEqual(existing.Object["spec"], spec) || !hasLabels(existing.GetLabels(), labels) {
+		existing.Object["spec"] = spec
+		merged := existing.GetLabels()
+		if merged == nil {
+			merged = map[string]string{}
+		}
+		for k, v := range labels {
+			merged[k] = v
+		}
+		existing.SetLabels(merged)
+		if err := r.Update(ctx, existing); err != nil {
+			return err
+		}
+		metrics.RecordChildOperation(serviceMonitorGVK.Kind, metrics.OperationUpdate)
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	k8sappsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appsv1 "github.com/gxanlvxgx/simple-app-operator/api/v1"
+	"github.com/gxanlvxgx/simple-app-operator/internal/metrics"
+)
+
+var _ = Describe("ensureServiceMonitor", func() {
+	var (
+		reconciler *SimpleAppReconciler
+		app        *appsv1.SimpleApp
+		key        = client.ObjectKey{Name: "web", Namespace: "default"}
+	)
+
+	BeforeEach(func() {
+		// A cluster with the Prometheus Operator CRDs installed
+		mapper := meta.NewDefaultRESTMapper(nil)
+		mapper.Add(serviceMonitorGVK, meta.RESTScopeNamespace)
+		reconciler = &SimpleAppReconciler{
+			Client:   fake.NewClientBuilder().WithScheme(scheme.Scheme).WithRESTMapper(mapper).Build(),
+			Scheme:   scheme.Scheme,
+			Recorder: record.NewFakeRecorder(100),
+		}
+		app = &appsv1.SimpleApp{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", UID: "uid-web"},
+			Spec: appsv1.SimpleAppSpec{
+				Image:         "nginx:latest",
+				ContainerPort: 8080,
+				Metrics:       &appsv1.MetricsSpec{Port: 9090, PortName: "metrics", Path: "/metrics", Interval: "15s"},
+			},
+		}
+	})
+
+	getServiceMonitor := func() (*unstructured.Unstructured, error) {
+		sm := &unstructured.Unstructured{}
+		sm.SetGroupVersionKind(serviceMonitorGVK)
+		return sm, reconciler.Get(ctx, key, sm)
+	}
+
+	It("creates a ServiceMonitor scraping the metrics port", func() {
+		Expect(reconciler.ensureServiceMonitor(ctx, app, "web")).To(Succeed())
+
+		sm, err := getServiceMonitor()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(metav1.IsControlledBy(sm, app)).To(BeTrue())
+		Expect(sm.GetLabels()).To(HaveKeyWithValue("app", "web"))
+		labels, _, _ := unstructured.NestedStringMap(sm.Object, "spec", "selector", "matchLabels")
+		Expect(labels).To(Equal(map[string]string{"app": "web"}))
+		endpoints, _, _ := unstructured.NestedSlice(sm.Object, "spec", "endpoints")
+		Expect(endpoints).To(ConsistOf(map[string]any{"port": "metrics", "path": "/metrics", "interval": "15s"}))
+	})
+
+	It("updates and removes the ServiceMonitor with the spec", func() {
+		operations := func(operation string) float64 {
+			return testutil.ToFloat64(metrics.ChildOperationsTotal.WithLabelValues("ServiceMonitor", operation))
+		}
+		creates, updates, deletes := operations(metrics.OperationCreate), operations(metrics.OperationUpdate), operations(metrics.OperationDelete)
+		Expect(reconciler.ensureServiceMonitor(ctx, app, "web")).To(Succeed())
+		Expect(operations(metrics.OperationCreate)).To(Equal(creates + 1))
+
+		app.Spec.Metrics.Interval = "1m"
+		Expect(reconciler.ensureServiceMonitor(ctx, app, "web")).To(Succeed())
+		sm, err := getServiceMonitor()
+		Expect(err).NotTo(HaveOccurred())
+		endpoints, _, _ := unstructured.NestedSlice(sm.Object, "spec", "endpoints")
+		Expect(endpoints).To(ConsistOf(HaveKeyWithValue("interval", "1m")))
+		Expect(operations(metrics.OperationUpdate)).To(Equal(updates + 1))
+
+		app.Spec.Metrics = nil
+		Expect(reconciler.ensureServiceMonitor(ctx, app, "web")).To(Succeed())
+		_, err = getServiceMonitor()
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+		Expect(operations(metrics.OperationDelete)).To(Equal(deletes + 1))
+	})
+
+	It("restores the common labels removed by hand", func() {
+		reconciler.CommonLabels = map[string]string{"team": "platform"}
+		Expect(reconciler.ensureServiceMonitor(ctx, app, "web")).To(Succeed())
+		sm, err := getServiceMonitor()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sm.GetLabels()).To(HaveKeyWithValue("team", "platform"))
+
+		sm.SetLabels(map[string]string{"app": "web"})
+		Expect(reconciler.Update(ctx, sm)).To(Succeed())
+		Expect(reconciler.ensureServiceMonitor(ctx, app, "web")).To(Succeed())
+		sm, err = getServiceMonitor()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sm.GetLabels()).To(HaveKeyWithValue("team", "platform"))
+	})
+
+	It("does nothing when the CRD is not installed", func() {
+		reconciler.Client = fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+		Expect(reconciler.ensureServiceMonitor(ctx, app, "web")).To(Succeed())
+	})
+})
+
+var _ = Describe("Prometheus scrape annotations", func() {
+	var (
+		reconciler *SimpleAppReconciler
+		app        *appsv1.SimpleApp
+		key        = client.ObjectKey{Name: "web", Namespace: "default"}
+	)
+
+	BeforeEach(func() {
+		reconciler = &SimpleAppReconciler{
+			Client:   fake.NewClientBuilder().WithScheme(scheme.Scheme).Build(),
+			Scheme:   scheme.Scheme,
+			Recorder: record.NewFakeRecorder(100),
+		}
+		app = &appsv1.SimpleApp{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", UID: "uid-web"},
+			Spec: appsv1.SimpleAppSpec{
+				Image:            "nginx:latest",
+				ContainerPort:    8080,
+				Replicas:         ptr.To[int32](1),
+				PrometheusScrape: &appsv1.PrometheusScrapeSpec{},
+			},
+		}
+	})
+
+	templateAnnotations := func() map[string]string {
+		deployment := &k8sappsv1.Deployment{}
+		Expect(reconciler.Get(ctx, key, deployment)).To(Succeed())
+		return deployment.Spec.Template.Annotations
+	}
+
+	It("stamps the scrape annotations on the pod template", func() {
+		_, err := reconciler.ensureDeployment(ctx, app, "web", 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(templateAnnotations()).To(Equal(map[string]string{
+			"prometheus.io/scrape": "true",
+			"prometheus.io/port":   "8080",
+			"prometheus.io/path":   "/metrics",
+		}))
+
+		By("pointing them at the metrics port")
+		app.Spec.Metrics = &appsv1.MetricsSpec{Port: 9090}
+		_, err = reconciler.ensureDeployment(ctx, app, "web", 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(templateAnnotations()).To(HaveKeyWithValue("prometheus.io/port", "9090"))
+	})
+
+	It("removes only the scrape annotations when disabled", func() {
+		_, err := reconciler.ensureDeployment(ctx, app, "web", 1)
+		Expect(err).NotTo(HaveOccurred())
+
+		deployment := &k8sappsv1.Deployment{}
+		Expect(reconciler.Get(ctx, key, deployment)).To(Succeed())
+		deployment.Spec.Template.Annotations["kubectl.kubernetes.io/restartedAt"] = "2025-01-01T00:00:00Z"
+		Expect(reconciler.Update(ctx, deployment)).To(Succeed())
+
+		app.Spec.PrometheusScrape = nil
+		_, err = reconciler.ensureDeployment(ctx, app, "web", 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(templateAnnotations()).To(Equal(map[string]string{
+			"kubectl.kubernetes.io/restartedAt": "2025-01-01T00:00:00Z",
+		}))
+	})
+})
@@ -18,25 +18,114 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"maps"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	"golang.org/x/time/rate"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	appsv1alpha1 "github.com/gxanlvxgx/simple-app-operator/api/v1"
+	"github.com/gxanlvxgx/simple-app-operator/internal/metrics"
 )
 
+// clusterDomain is the DNS suffix used when reporting the Service address in status
+const clusterDomain = "cluster.local"
+
+// appContainerName is the default name of the application container in the pod template.
+const appContainerName = "app"
+
+// specHashAnnotation records on the Deployment and Service a hash of the spec the controller last
+// applied, so changes to any field of the desired spec are detected without diffing each one.
+const specHashAnnotation = "simpleapp.myapp.io/spec-hash"
+
+// legacySpecHashAnnotation is where earlier versions recorded the spec hash. It is still read, so
+// upgrading doesn't update every child, and removed when the child is next written.
+const legacySpecHashAnnotation = "apps.myapp.io/spec-hash"
+
+// changeCauseAnnotation is shown as the CHANGE-CAUSE of a revision by kubectl rollout history. The
+// Deployment controller copies it onto the ReplicaSet of the revision being rolled out.
+const changeCauseAnnotation = "kubernetes.io/change-cause"
+
+// suspendedMessage is the status message of a suspended SimpleApp.
+const suspendedMessage = "Suspended"
+
+// scaledToZeroMessage is the status message of a SimpleApp running no replicas.
+const scaledToZeroMessage = "Scaled to zero"
+
 // SimpleAppReconciler reconciles a SimpleApp object
 type SimpleAppReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+
+	// ResyncPeriod, when non-zero, requeues every successfully reconciled SimpleApp
+	// after this interval so drift is corrected even without watch events.
+	ResyncPeriod time.Duration
+
+	// Notifier, when set, announces Ready/Degraded transitions to a webhook.
+	Notifier *Notifier
+
+	// APIReader reads directly from the API server. When set, it double-checks children the
+	// cache reports as missing right before creating them, so a cache that hasn't caught up doesn't
+	// cause duplicate creates.
+	APIReader client.Reader
+
+	// CommonLabels are added to every Deployment and Service the controller manages, next to the
+	// app label, and restored when removed. The app label wins over a common label with the same key.
+	CommonLabels map[string]string
+
+	// MaxConcurrentReconciles is the number of SimpleApps reconciled in parallel; 0 means 1.
+	// A single SimpleApp is never reconciled by two workers at once.
+	MaxConcurrentReconciles int
+
+	// DisableServiceManagement leaves Services to another controller, e.g. during a migration to a
+	// separate networking controller: no Service is created, updated or deleted, whatever the
+	// SimpleApp says. The Service the app would use is still reported in its status.
+	DisableServiceManagement bool
+
+	// BaseBackoff and MaxBackoff bound the exponential backoff of a SimpleApp whose reconciles keep
+	// failing; zero keeps the controller-runtime defaults of 5ms and 1000s. Failed reconciles of all
+	// SimpleApps are retried at 10 per second at most (bursts of 100), like by default.
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+
+	// ResyncJitter spreads the resync of each SimpleApp over up to this fraction of ResyncPeriod
+	// (e.g. 0.1 for 10%), so apps reconciled together don't all requeue together.
+	ResyncJitter float64
+
+	// ServicePortFollowsContainerPort defaults an omitted spec.servicePort to spec.containerPort
+	// instead of DefaultServicePort, like the defaulting webhook started with the same flag.
+	ServicePortFollowsContainerPort bool
 }
 
 // RBAC Permissions
@@ -44,13 +133,27 @@ type SimpleAppReconciler struct {
 //+kubebuilder:rbac:groups=apps.myapp.io,resources=simpleapps/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=apps.myapp.io,resources=simpleapps/finalizers,verbs=update
 //+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
 //+kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 //+kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create
+//+kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
-func (r *SimpleAppReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	log := log.FromContext(ctx)
+func (r *SimpleAppReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, err error) {
+	start := time.Now()
+	defer func() { metrics.RecordReconcile(start, err) }()
+	log := logf.FromContext(ctx)
 
 	// 1. Fetch the SimpleApp instance
 	var simpleApp appsv1alpha1.SimpleApp
@@ -58,149 +161,1384 @@ func (r *SimpleAppReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	// 2. Ensure the Deployment exists and matches the desired state
-	deployment, err := r.ensureDeployment(ctx, &simpleApp)
+	// Honour a per-object log level so a single app can be debugged without raising global verbosity
+	if value, ok := simpleApp.Annotations[appsv1alpha1.LogLevelAnnotation]; ok {
+		if verbosity, err := parseLogLevel(value); err != nil {
+			log.Error(err, "Ignoring invalid log level annotation", "Annotation", appsv1alpha1.LogLevelAnnotation)
+		} else {
+			log = withVerbosity(log, verbosity)
+			ctx = logf.IntoContext(ctx, log)
+		}
+	}
+	log.V(1).Info("Reconciling SimpleApp", "Namespace", simpleApp.Namespace, "Name", simpleApp.Name)
+
+	// 2. Compute the name of the child resources (honours spec.nameTemplate)
+	name, err := childName(&simpleApp)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Run the pre-delete Job of a deleted SimpleApp; its children are garbage collected afterwards
+	if !simpleApp.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, r.finalize(ctx, &simpleApp, name)
+	}
+	if err := r.ensurePreDeleteFinalizer(ctx, &simpleApp); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Leave the children of a suspended SimpleApp alone until it is resumed
+	if ptr.Deref(simpleApp.Spec.Suspend, false) {
+		log.V(1).Info("SimpleApp is suspended, skipping", "Namespace", simpleApp.Namespace, "Name", simpleApp.Name)
+		if simpleApp.Status.Message != suspendedMessage || simpleApp.Status.ObservedGeneration != simpleApp.Generation {
+			simpleApp.Status.Message = suspendedMessage
+			simpleApp.Status.ObservedGeneration = simpleApp.Generation
+			simpleApp.Status.LastReconcileTime = ptr.To(metav1.Now())
+			if err := r.Status().Update(ctx, &simpleApp); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// Run-once apps get a Job instead of a Deployment and the children around it
+	if simpleApp.Spec.RunOnce {
+		return r.reconcileRunOnce(ctx, &simpleApp, name)
+	}
+
+	// 3. Ensure the Deployment exists and matches the desired state, after the
+	// PersistentVolumeClaim of spec.storage its pods mount
+	if err := r.ensurePVC(ctx, &simpleApp, name); err != nil {
+		return ctrl.Result{}, err
+	}
+	// Shared by the Deployment, its canary and the PodDisruptionBudget, so an invalid override is
+	// reported and the nodes are listed once per reconcile
+	replicas, err := r.replicaCount(ctx, &simpleApp)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	// With spec.autoRollback, a new image stuck past the progress deadline goes back to the last good
+	// one. It and ensureDeployment only set status.failedImage and the ReferencesMissing condition in
+	// memory, so keep the status as read to tell whether the status must be written at the end
+	observedStatus := simpleApp.Status.DeepCopy()
+	if err := r.autoRollback(ctx, &simpleApp, name); err != nil {
+		return ctrl.Result{}, err
+	}
+	deployment, err := r.ensureDeployment(ctx, &simpleApp, name, replicas)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	// The canary Deployment of spec.canary runs its share of the replicas next to it
+	canary, err := r.ensureCanaryDeployment(ctx, &simpleApp, name, replicas)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// 4. Ensure the Service exists and matches the desired state
+	service, err := r.ensureService(ctx, &simpleApp, name)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 
-	// 3. Ensure the Service exists and matches the desired state
-	_, err = r.ensureService(ctx, &simpleApp)
-	if err != nil {
-		return ctrl.Result{}, err
+	// 5. Ensure the Ingress exists and matches the desired state (Infrastructure agnostic)
+	_, err = r.ensureIngress(ctx, &simpleApp, name)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// 6. Ensure the PodDisruptionBudget and NetworkPolicy match spec.podDisruptionBudget and
+	// spec.networkPolicy (removed when unset)
+	if err := r.ensurePDB(ctx, &simpleApp, name, replicas); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.ensureNetworkPolicy(ctx, &simpleApp, name); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// 7. Ensure the ServiceMonitor matches spec.metrics (only with the Prometheus Operator installed)
+	if err := r.ensureServiceMonitor(ctx, &simpleApp, name); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// 8. Ensure the HorizontalPodAutoscaler matches spec.autoscaling (removed when unset)
+	if err := r.ensureHPA(ctx, &simpleApp, name); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// 9. Remove children left behind under a previous name (e.g. after a nameTemplate change)
+	if err := r.pruneRenamedChildren(ctx, &simpleApp, name); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// 10. Detect pods matched by our selector that don't belong to this SimpleApp
+	pods, foreign, err := r.appPods(ctx, &simpleApp, deployment, canary)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	var canaryStatus *appsv1alpha1.CanaryStatus
+	if canary != nil {
+		if canaryStatus, err = r.canaryStatus(ctx, &simpleApp, canary); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	collision := selectorCollisionCondition(&simpleApp, deployment, foreign)
+	if collision.Status == metav1.ConditionTrue &&
+		!meta.IsStatusConditionTrue(simpleApp.Status.Conditions, appsv1alpha1.ConditionSelectorCollision) {
+		r.Recorder.Event(&simpleApp, corev1.EventTypeWarning, collision.Reason, collision.Message)
+	}
+
+	// 11. Update CR Status with the current state of the Deployment
+	ready := readyCondition(&simpleApp, deployment)
+	previous := meta.FindStatusCondition(simpleApp.Status.Conditions, appsv1alpha1.ConditionReady)
+	transitioned := previous != nil && previous.Status != ready.Status
+
+	status := simpleApp.Status.DeepCopy()
+	status.ReadyReplicas = deployment.Status.ReadyReplicas
+	status.UnavailableReplicas = deployment.Status.UnavailableReplicas
+	status.UpdatedReplicas = deployment.Status.UpdatedReplicas
+	status.TotalRestarts = totalRestarts(pods)
+	status.Message = rolloutMessage(deployment, pods)
+	if status.Message == "" && desiredReplicas(&simpleApp, deployment) == 0 {
+		status.Message = scaledToZeroMessage
+	}
+	if status.Message == "" && imageRollingOut(&simpleApp, deployment) {
+		status.Message = imageRolloutMessage(runningImage(&simpleApp, deployment))
+	}
+	status.ServiceDNS = r.serviceDNS(&simpleApp, service)
+	status.RunningImage = runningImage(&simpleApp, deployment)
+	status.LastGoodImage = lastGoodImage(&simpleApp, deployment)
+	status.FailedImage = failedImage(&simpleApp)
+	status.ServiceEndpoint = serviceEndpoint(service)
+	status.Canary = canaryStatus
+	status.Summary = r.statusSummary(&simpleApp, deployment, service)
+	status.ServiceStatus = ""
+	if stuck := progressDeadlineExceeded(deployment); stuck != nil {
+		status.ServiceStatus = appsv1alpha1.ServiceStatusFailed
+		if simpleApp.Status.ServiceStatus != appsv1alpha1.ServiceStatusFailed {
+			r.Recorder.Eventf(&simpleApp, corev1.EventTypeWarning, progressDeadlineExceededReason,
+				"Rollout of Deployment %s made no progress within its deadline: %s", deployment.Name, stuck.Message)
+		}
+	}
+	meta.SetStatusCondition(&status.Conditions, ready)
+	meta.SetStatusCondition(&status.Conditions, collision)
+	if simpleApp.Spec.Paused {
+		meta.SetStatusCondition(&status.Conditions, pausedCondition(&simpleApp))
+	} else {
+		meta.RemoveStatusCondition(&status.Conditions, appsv1alpha1.ConditionPaused)
+	}
+	// Left behind when spec.runOnce was turned off
+	meta.RemoveStatusCondition(&status.Conditions, appsv1alpha1.ConditionComplete)
+	status.ObservedGeneration = simpleApp.Generation
+	if !equality.Semantic.DeepEqual(*status, *observedStatus) {
+		status.LastReconcileTime = ptr.To(metav1.Now())
+		log.V(1).Info("Updating SimpleApp status", "Namespace", simpleApp.Namespace, "Name", simpleApp.Name,
+			"ReadyReplicas", status.ReadyReplicas, "Ready", ready.Status, "Reason", ready.Reason)
+		simpleApp.Status = *status
+		if err := r.Status().Update(ctx, &simpleApp); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	// 12. Announce Ready/Degraded transitions; delivery problems never fail the reconcile
+	if transitioned {
+		r.notifyReadiness(ctx, &simpleApp, ready)
+	}
+
+	log.Info("Successfully reconciled SimpleApp", "Namespace", simpleApp.Namespace, "Name", simpleApp.Name, "Image", simpleApp.Spec.Image)
+	return ctrl.Result{RequeueAfter: r.requeueAfter(&simpleApp)}, nil
+}
+
+// ensureDeployment creates or updates the Deployment based on the CR specs. replicas is the
+// replicaCount of the app, the canary's share included.
+func (r *SimpleAppReconciler) ensureDeployment(ctx context.Context, cr *appsv1alpha1.SimpleApp, name string, replicas int32) (*appsv1.Deployment, error) {
+	log := logf.FromContext(ctx).WithValues("Deployment", name, "Namespace", cr.Namespace)
+	desiredReplicas := replicas
+	if cr.Spec.Canary != nil {
+		desiredReplicas -= canaryReplicas(desiredReplicas, cr.Spec.Canary.Weight)
+	}
+
+	// Missing ConfigMaps/Secrets don't block the rollout: pods wait until they appear
+	if err := r.warnMissingReferences(ctx, cr); err != nil {
+		return nil, err
+	}
+
+	dep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: cr.Namespace,
+			Labels:    r.deploymentLabels(cr),
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas:                &desiredReplicas,
+			ProgressDeadlineSeconds: progressDeadlineSeconds(cr),
+			Paused:                  cr.Spec.Paused,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: appLabels(cr),
+			},
+			Template: podTemplate(cr, name),
+		},
+	}
+
+	hashed := dep.Spec.DeepCopy()
+	if !managesReplicas(cr) {
+		// Scaling events must not look like a spec change
+		hashed.Replicas = nil
+	}
+	metav1.SetMetaDataAnnotation(&dep.ObjectMeta, specHashAnnotation, specHash(hashed))
+	if err := ctrl.SetControllerReference(cr, dep, r.Scheme); err != nil {
+		return nil, err
+	}
+
+	var existing appsv1.Deployment
+	err := r.getChild(ctx, client.ObjectKey{Name: dep.Name, Namespace: dep.Namespace}, &existing)
+	if err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return nil, err
+		}
+		log.V(1).Info("Creating Deployment")
+		metav1.SetMetaDataAnnotation(&dep.ObjectMeta, changeCauseAnnotation, changeCause(cr, runningImage(cr, dep)))
+		if err := r.Create(ctx, dep); err != nil {
+			return nil, err
+		}
+		metrics.RecordChildOperation("Deployment", metrics.OperationCreate)
+		return dep, nil
+	}
+
+	// Patch with an optimistic lock so fields owned by other controllers are left alone,
+	// and re-read the Deployment whenever someone else modified it in between.
+	desired := dep
+	if !managesReplicas(cr) {
+		// The HorizontalPodAutoscaler, or whoever scales it by hand, owns the replica count
+		desired = dep.DeepCopy()
+		desired.Spec.Replicas = nil
+	}
+	// getChild again, since the Deployment may only be known to the API server so far
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := r.getChild(ctx, client.ObjectKeyFromObject(dep), &existing); err != nil {
+			return err
+		}
+		original := existing.DeepCopy()
+		patch := client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{})
+		previousImage := runningImage(cr, &existing)
+		changed := syncDeployment(&existing, desired)
+		// Only a new pod template starts a revision; stamping scaling changes would relabel the current one
+		if !equality.Semantic.DeepEqual(original.Spec.Template, existing.Spec.Template) {
+			metav1.SetMetaDataAnnotation(&existing.ObjectMeta, changeCauseAnnotation, changeCause(cr, runningImage(cr, &existing)))
+		}
+		adopted, err := r.adoptChild(&existing, cr)
+		if err != nil {
+			return err
+		}
+		if adopted {
+			changed = append(changed, "ownerReference")
+		}
+		if len(changed) == 0 {
+			log.V(1).Info("Deployment up to date")
+			return nil
+		}
+		log.V(1).Info("Updating Deployment", "Changed", changed)
+		if err := r.Patch(ctx, &existing, patch); err != nil {
+			return err
+		}
+		metrics.RecordChildOperation("Deployment", metrics.OperationUpdate)
+		if image := runningImage(cr, &existing); previousImage != "" && image != previousImage {
+			r.Recorder.Eventf(cr, corev1.EventTypeNormal, "ImageUpdated", "Rolling out image %s -> %s", previousImage, image)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &existing, nil
+}
+
+// templateAnnotationKeys are the pod template annotations managed by the controller.
+var templateAnnotationKeys = append([]string{appsv1alpha1.RolloutTriggerAnnotation}, scrapeAnnotationKeys...)
+
+// templateAnnotations returns the managed pod template annotations: the scrape annotations and
+// spec.rolloutTrigger.
+func templateAnnotations(cr *appsv1alpha1.SimpleApp) map[string]string {
+	annotations := scrapeAnnotations(cr)
+	if cr.Spec.RolloutTrigger != "" {
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[appsv1alpha1.RolloutTriggerAnnotation] = cr.Spec.RolloutTrigger
+	}
+	return annotations
+}
+
+// podTemplate builds the pod template of the app, shared by its Deployment and, for
+// spec.runOnce, its Job.
+func podTemplate(cr *appsv1alpha1.SimpleApp, name string) corev1.PodTemplateSpec {
+	volumes, volumeMounts := appVolumes(cr)
+	if cr.Spec.Storage != nil {
+		volume, mount := storageVolume(cr, name)
+		volumes = append(volumes, volume)
+		volumeMounts = append(volumeMounts, mount)
+	}
+	template := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      podLabels(cr),
+			Annotations: templateAnnotations(cr),
+		},
+		Spec: corev1.PodSpec{
+			ServiceAccountName:            cr.Spec.ServiceAccountName,
+			AutomountServiceAccountToken:  cr.Spec.AutomountServiceAccountToken,
+			Subdomain:                     podSubdomain(cr, name),
+			TerminationGracePeriodSeconds: terminationGracePeriod(cr),
+			NodeSelector:                  nodeSelector(cr),
+			Tolerations:                   cr.Spec.Tolerations,
+			Affinity:                      cr.Spec.Affinity,
+			TopologySpreadConstraints:     topologySpreadConstraints(cr),
+			PriorityClassName:             cr.Spec.PriorityClassName,
+			HostAliases:                   cr.Spec.HostAliases,
+			DNSPolicy:                     dnsPolicy(cr),
+			DNSConfig:                     cr.Spec.DNSConfig,
+			Volumes:                       volumes,
+			InitContainers:                defaultedContainers(cr.Spec.InitContainers),
+			SecurityContext:               podSecurityContext(cr),
+			Containers: []corev1.Container{{
+				Name:            containerName(cr),
+				Image:           appImage(cr),
+				ImagePullPolicy: imagePullPolicy(cr),
+				Ports:           containerPorts(cr),
+				Env:             containerEnv(cr),
+				EnvFrom:         secretEnvFrom(cr),
+				VolumeMounts:    volumeMounts,
+				Lifecycle:       containerLifecycle(cr),
+				StartupProbe:    startupProbe(cr),
+				WorkingDir:      cr.Spec.WorkingDir,
+				Stdin:           cr.Spec.Stdin,
+				TTY:             cr.Spec.TTY,
+				SecurityContext: cr.Spec.SecurityContext,
+				Resources:       gpuResources(cr),
+			}},
+		},
+	}
+	// Sidecars go after the application container, which stays at index 0
+	template.Spec.Containers = append(template.Spec.Containers, defaultedContainers(cr.Spec.Sidecars)...)
+	return template
+}
+
+// getChild reads a child object from the cache, confirming with the API server before
+// reporting it as not found. It is meant for children that are created when missing, and for
+// re-reading them before an update; a plain Get from the cache is enough to delete one.
+func (r *SimpleAppReconciler) getChild(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	err := r.Get(ctx, key, obj)
+	if !apierrors.IsNotFound(err) {
+		return err
+	}
+	if exists, apiErr := r.childExists(ctx, key, obj); apiErr != nil || exists {
+		return apiErr
+	}
+	return err
+}
+
+// childExists asks the API server whether a child the cache reports as missing exists, reading it
+// into obj, right before the child would be created. Without an APIReader the cache is trusted.
+func (r *SimpleAppReconciler) childExists(ctx context.Context, key client.ObjectKey, obj client.Object) (bool, error) {
+	if r.APIReader == nil {
+		return false, nil
+	}
+	err := r.APIReader.Get(ctx, key, obj)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// adoptChild sets cr as the controller of a child without one (e.g. a Deployment migrated from a
+// raw manifest), so it is garbage collected with the app. It also takes over a child left behind by
+// a deleted SimpleApp of the same name (e.g. one that was deleted and recreated with a different
+// spec); without that the garbage collector would delete the child once it notices the old owner is
+// gone, taking the running app down with it. Children controlled by anything else are left alone.
+func (r *SimpleAppReconciler) adoptChild(obj client.Object, cr *appsv1alpha1.SimpleApp) (bool, error) {
+	owner := metav1.GetControllerOf(obj)
+	if owner != nil {
+		if owner.UID == cr.UID || owner.Kind != "SimpleApp" || owner.Name != cr.Name ||
+			owner.APIVersion != appsv1alpha1.GroupVersion.String() {
+			return false, nil
+		}
+		var refs []metav1.OwnerReference
+		for _, ref := range obj.GetOwnerReferences() {
+			if ref.UID != owner.UID {
+				refs = append(refs, ref)
+			}
+		}
+		obj.SetOwnerReferences(refs)
+	}
+	if err := ctrl.SetControllerReference(cr, obj, r.Scheme); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// appliedSpecHash returns the spec hash recorded on a Deployment or Service, empty if none.
+func appliedSpecHash(obj metav1.Object) string {
+	if hash, ok := obj.GetAnnotations()[specHashAnnotation]; ok {
+		return hash
+	}
+	return obj.GetAnnotations()[legacySpecHashAnnotation]
+}
+
+// setSpecHash records hash on a Deployment or Service, dropping the legacy annotation.
+func setSpecHash(obj metav1.Object, hash string) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	delete(annotations, legacySpecHashAnnotation)
+	annotations[specHashAnnotation] = hash
+	obj.SetAnnotations(annotations)
+}
+
+// specHash returns a short, stable hash of a Deployment or Service spec.
+func specHash(spec any) string {
+	// Marshalling API types can't fail
+	data, _ := json.Marshal(spec)
+	h := fnv.New32a()
+	_, _ = h.Write(data)
+	return fmt.Sprintf("%08x", h.Sum32())
+}
+
+// syncDeployment copies the fields managed by the controller from desired onto existing
+// and returns the ones that differed, nil when nothing changed. A nil desired replica count
+// leaves scaling alone. A changed spec hash means the desired state moved on; the field
+// comparisons catch out-of-band edits to the live Deployment.
+// The application container is the first desired container; it is looked up by name in existing,
+// and a renamed one replaces all containers.
+func syncDeployment(existing, desired *appsv1.Deployment) []string {
+	var changed []string
+	desiredApp := &desired.Spec.Template.Spec.Containers[0]
+	i := containerIndex(existing.Spec.Template.Spec.Containers, desiredApp.Name)
+	if i < 0 {
+		changed = append(changed, "containerName")
+		existing.Spec.Template.Spec.Containers = append([]corev1.Container(nil), desired.Spec.Template.Spec.Containers...)
+		i = 0
+	}
+	existingApp := &existing.Spec.Template.Spec.Containers[i]
+	existingSidecars := append(existing.Spec.Template.Spec.Containers[:i:i], existing.Spec.Template.Spec.Containers[i+1:]...)
+	if appliedSpecHash(existing) != desired.Annotations[specHashAnnotation] {
+		changed = append(changed, "specHash")
+	}
+	if desired.Spec.Replicas != nil && *existing.Spec.Replicas != *desired.Spec.Replicas {
+		changed = append(changed, "replicas")
+	}
+	for k, v := range desired.Labels {
+		if existing.Labels[k] != v {
+			changed = append(changed, "labels")
+			break
+		}
+	}
+	for k, v := range desired.Spec.Template.Labels {
+		if existing.Spec.Template.Labels[k] != v {
+			changed = append(changed, "podLabels")
+			break
+		}
+	}
+	// Only the scrape and rollout trigger annotations are managed; others (e.g. from kubectl rollout restart) are kept
+	for _, k := range scrapeAnnotationKeys {
+		if existing.Spec.Template.Annotations[k] != desired.Spec.Template.Annotations[k] {
+			changed = append(changed, "scrapeAnnotations")
+			break
+		}
+	}
+	if existing.Spec.Template.Annotations[appsv1alpha1.RolloutTriggerAnnotation] !=
+		desired.Spec.Template.Annotations[appsv1alpha1.RolloutTriggerAnnotation] {
+		changed = append(changed, "rolloutTrigger")
+	}
+	if existingApp.Image != desiredApp.Image ||
+		existingApp.ImagePullPolicy != desiredApp.ImagePullPolicy {
+		changed = append(changed, "image")
+	}
+	if !equality.Semantic.DeepEqual(existingApp.Ports, desiredApp.Ports) {
+		changed = append(changed, "ports")
+	}
+	if existingApp.WorkingDir != desiredApp.WorkingDir {
+		changed = append(changed, "workingDir")
+	}
+	if existingApp.Stdin != desiredApp.Stdin ||
+		existingApp.TTY != desiredApp.TTY {
+		changed = append(changed, "stdin")
+	}
+	if existing.Spec.Template.Spec.ServiceAccountName != desired.Spec.Template.Spec.ServiceAccountName ||
+		existing.Spec.Template.Spec.Subdomain != desired.Spec.Template.Spec.Subdomain ||
+		!equality.Semantic.DeepEqual(existing.Spec.Template.Spec.AutomountServiceAccountToken, desired.Spec.Template.Spec.AutomountServiceAccountToken) {
+		changed = append(changed, "serviceAccount")
+	}
+	if !equality.Semantic.DeepEqual(existing.Spec.Template.Spec.Volumes, desired.Spec.Template.Spec.Volumes) ||
+		!equality.Semantic.DeepEqual(existingApp.VolumeMounts, desiredApp.VolumeMounts) {
+		changed = append(changed, "volumes")
+	}
+	if !equality.Semantic.DeepEqual(existingApp.EnvFrom, desiredApp.EnvFrom) {
+		changed = append(changed, "envFrom")
+	}
+	if !equality.Semantic.DeepEqual(existingApp.Env, desiredApp.Env) {
+		changed = append(changed, "env")
+	}
+	if !equality.Semantic.DeepEqual(existing.Spec.Template.Spec.InitContainers, desired.Spec.Template.Spec.InitContainers) ||
+		!equality.Semantic.DeepEqual(existingSidecars, desired.Spec.Template.Spec.Containers[1:]) {
+		changed = append(changed, "containers")
+	}
+	if !equality.Semantic.DeepEqual(existing.Spec.Template.Spec.SecurityContext, desired.Spec.Template.Spec.SecurityContext) ||
+		!equality.Semantic.DeepEqual(existingApp.SecurityContext, desiredApp.SecurityContext) {
+		changed = append(changed, "securityContext")
+	}
+	if !equality.Semantic.DeepEqual(existing.Spec.Template.Spec.TerminationGracePeriodSeconds, desired.Spec.Template.Spec.TerminationGracePeriodSeconds) ||
+		!equality.Semantic.DeepEqual(existingApp.Lifecycle, desiredApp.Lifecycle) ||
+		!equality.Semantic.DeepEqual(existingApp.StartupProbe, desiredApp.StartupProbe) {
+		changed = append(changed, "lifecycle")
+	}
+	if !equality.Semantic.DeepEqual(existingApp.Resources.Limits[gpuResourceName], desiredApp.Resources.Limits[gpuResourceName]) {
+		changed = append(changed, "gpus")
+	}
+	if !equality.Semantic.DeepEqual(existing.Spec.Template.Spec.NodeSelector, desired.Spec.Template.Spec.NodeSelector) ||
+		!equality.Semantic.DeepEqual(existing.Spec.Template.Spec.Tolerations, desired.Spec.Template.Spec.Tolerations) ||
+		!equality.Semantic.DeepEqual(existing.Spec.Template.Spec.Affinity, desired.Spec.Template.Spec.Affinity) ||
+		!equality.Semantic.DeepEqual(existing.Spec.Template.Spec.TopologySpreadConstraints, desired.Spec.Template.Spec.TopologySpreadConstraints) ||
+		existing.Spec.Template.Spec.PriorityClassName != desired.Spec.Template.Spec.PriorityClassName {
+		changed = append(changed, "scheduling")
+	}
+	if !equality.Semantic.DeepEqual(existing.Spec.Template.Spec.HostAliases, desired.Spec.Template.Spec.HostAliases) ||
+		existing.Spec.Template.Spec.DNSPolicy != desired.Spec.Template.Spec.DNSPolicy ||
+		!equality.Semantic.DeepEqual(existing.Spec.Template.Spec.DNSConfig, desired.Spec.Template.Spec.DNSConfig) {
+		changed = append(changed, "dns")
+	}
+	if !equality.Semantic.DeepEqual(existing.Spec.ProgressDeadlineSeconds, desired.Spec.ProgressDeadlineSeconds) {
+		changed = append(changed, "progressDeadlineSeconds")
+	}
+	if existing.Spec.Paused != desired.Spec.Paused {
+		changed = append(changed, "paused")
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	setSpecHash(existing, desired.Annotations[specHashAnnotation])
+	if desired.Spec.Replicas != nil {
+		existing.Spec.Replicas = desired.Spec.Replicas
+	}
+	existing.Spec.ProgressDeadlineSeconds = desired.Spec.ProgressDeadlineSeconds
+	existing.Spec.Paused = desired.Spec.Paused
+	for k, v := range desired.Labels {
+		metav1.SetMetaDataLabel(&existing.ObjectMeta, k, v)
+	}
+	for k, v := range desired.Spec.Template.Labels {
+		metav1.SetMetaDataLabel(&existing.Spec.Template.ObjectMeta, k, v)
+	}
+	for _, k := range templateAnnotationKeys {
+		if v, ok := desired.Spec.Template.Annotations[k]; ok {
+			metav1.SetMetaDataAnnotation(&existing.Spec.Template.ObjectMeta, k, v)
+		} else {
+			delete(existing.Spec.Template.Annotations, k)
+		}
+	}
+	existingApp.Image = desiredApp.Image
+	existingApp.ImagePullPolicy = desiredApp.ImagePullPolicy
+	existingApp.Ports = desiredApp.Ports
+	existingApp.WorkingDir = desiredApp.WorkingDir
+	existingApp.Stdin = desiredApp.Stdin
+	existingApp.TTY = desiredApp.TTY
+	existing.Spec.Template.Spec.ServiceAccountName = desired.Spec.Template.Spec.ServiceAccountName
+	// The API server keeps the deprecated serviceAccount alias in sync; clear it too,
+	// otherwise it would resurrect the old name when ServiceAccountName is emptied.
+	existing.Spec.Template.Spec.DeprecatedServiceAccount = desired.Spec.Template.Spec.ServiceAccountName
+	existing.Spec.Template.Spec.AutomountServiceAccountToken = desired.Spec.Template.Spec.AutomountServiceAccountToken
+	existing.Spec.Template.Spec.Subdomain = desired.Spec.Template.Spec.Subdomain
+	existing.Spec.Template.Spec.Volumes = desired.Spec.Template.Spec.Volumes
+	existingApp.VolumeMounts = desiredApp.VolumeMounts
+	existingApp.EnvFrom = desiredApp.EnvFrom
+	existingApp.Env = desiredApp.Env
+	existing.Spec.Template.Spec.InitContainers = desired.Spec.Template.Spec.InitContainers
+	existing.Spec.Template.Spec.SecurityContext = desired.Spec.Template.Spec.SecurityContext
+	existingApp.SecurityContext = desiredApp.SecurityContext
+	existing.Spec.Template.Spec.TerminationGracePeriodSeconds = desired.Spec.Template.Spec.TerminationGracePeriodSeconds
+	existingApp.Lifecycle = desiredApp.Lifecycle
+	existingApp.StartupProbe = desiredApp.StartupProbe
+	syncGPUs(&existingApp.Resources, desiredApp.Resources)
+	existing.Spec.Template.Spec.NodeSelector = desired.Spec.Template.Spec.NodeSelector
+	existing.Spec.Template.Spec.Tolerations = desired.Spec.Template.Spec.Tolerations
+	existing.Spec.Template.Spec.Affinity = desired.Spec.Template.Spec.Affinity
+	existing.Spec.Template.Spec.TopologySpreadConstraints = desired.Spec.Template.Spec.TopologySpreadConstraints
+	existing.Spec.Template.Spec.PriorityClassName = desired.Spec.Template.Spec.PriorityClassName
+	existing.Spec.Template.Spec.HostAliases = desired.Spec.Template.Spec.HostAliases
+	existing.Spec.Template.Spec.DNSPolicy = desired.Spec.Template.Spec.DNSPolicy
+	existing.Spec.Template.Spec.DNSConfig = desired.Spec.Template.Spec.DNSConfig
+	// The application container goes first, followed by the sidecars
+	existing.Spec.Template.Spec.Containers = append([]corev1.Container{*existingApp}, desired.Spec.Template.Spec.Containers[1:]...)
+	return changed
+}
+
+// containerIndex returns the index of the container with the given name, or -1.
+func containerIndex(containers []corev1.Container, name string) int {
+	for i := range containers {
+		if containers[i].Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// appVolumes translates spec.volumes into pod volumes and the matching container mounts.
+// ConfigMaps are mounted read-only; emptyDirs and claims are writable.
+func appVolumes(cr *appsv1alpha1.SimpleApp) ([]corev1.Volume, []corev1.VolumeMount) {
+	var volumes []corev1.Volume
+	var mounts []corev1.VolumeMount
+	for _, v := range cr.Spec.Volumes {
+		volume := corev1.Volume{Name: v.Name}
+		mount := corev1.VolumeMount{Name: v.Name, MountPath: v.MountPath}
+		switch {
+		case v.EmptyDir != nil:
+			volume.EmptyDir = v.EmptyDir.DeepCopy()
+		case v.PersistentVolumeClaim != "":
+			volume.PersistentVolumeClaim = &corev1.PersistentVolumeClaimVolumeSource{ClaimName: v.PersistentVolumeClaim}
+		default:
+			volume.ConfigMap = &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: v.ConfigMap},
+				// Mirror the API server default so the update diff stays stable
+				DefaultMode: ptr.To(corev1.ConfigMapVolumeSourceDefaultMode),
+			}
+			mount.ReadOnly = true
+		}
+		volumes = append(volumes, volume)
+		mounts = append(mounts, mount)
+	}
+	return volumes, mounts
+}
+
+// secretEnvFrom exposes every Secret listed in spec.envFromSecret as environment variables.
+func secretEnvFrom(cr *appsv1alpha1.SimpleApp) []corev1.EnvFromSource {
+	var envFrom []corev1.EnvFromSource
+	for _, name := range cr.Spec.EnvFromSecret {
+		envFrom = append(envFrom, corev1.EnvFromSource{
+			SecretRef: &corev1.SecretEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: name},
+			},
+		})
+	}
+	return envFrom
+}
+
+// nodeSelector returns the node selector of the pods: spec.nodeSelector plus the architecture
+// label of spec.nodeArch.
+func nodeSelector(cr *appsv1alpha1.SimpleApp) map[string]string {
+	if cr.Spec.NodeArch == "" {
+		return cr.Spec.NodeSelector
+	}
+	selector := maps.Clone(cr.Spec.NodeSelector)
+	if selector == nil {
+		selector = map[string]string{}
+	}
+	selector[corev1.LabelArchStable] = cr.Spec.NodeArch
+	return selector
+}
+
+// logLevelEnvVar is the environment variable spec.logLevel sets in the application container.
+const logLevelEnvVar = "LOG_LEVEL"
+
+// containerEnv returns the environment variables of the application container: LOG_LEVEL for
+// spec.logLevel, nothing without it. Env vars win over those of envFrom Secrets.
+func containerEnv(cr *appsv1alpha1.SimpleApp) []corev1.EnvVar {
+	if cr.Spec.LogLevel == "" {
+		return nil
+	}
+	return []corev1.EnvVar{{Name: logLevelEnvVar, Value: cr.Spec.LogLevel}}
+}
+
+// containerName returns the name of the application container, spec.containerName or "app".
+func containerName(cr *appsv1alpha1.SimpleApp) string {
+	if cr.Spec.ContainerName == "" {
+		return appContainerName
+	}
+	return cr.Spec.ContainerName
+}
+
+// topologySpreadConstraints returns spec.topologySpreadConstraints, pointing constraints without
+// a label selector at the app's pods, since the app label value isn't always the CR name.
+func topologySpreadConstraints(cr *appsv1alpha1.SimpleApp) []corev1.TopologySpreadConstraint {
+	var constraints []corev1.TopologySpreadConstraint
+	for _, c := range cr.Spec.TopologySpreadConstraints {
+		c := *c.DeepCopy()
+		if c.LabelSelector == nil {
+			c.LabelSelector = &metav1.LabelSelector{MatchLabels: appLabels(cr)}
+		}
+		constraints = append(constraints, c)
+	}
+	return constraints
+}
+
+// replicaCount returns the replica count of the Deployment: the ReplicasOverrideAnnotation (or
+// its deprecated key) when present, otherwise the number of schedulable nodes with spec.replicasFromNodeCount, and
+// spec.replicas by default. An invalid override is reported with a Warning event and ignored.
+func (r *SimpleAppReconciler) replicaCount(ctx context.Context, cr *appsv1alpha1.SimpleApp) (int32, error) {
+	base, source := specReplicas(cr), "spec.replicas"
+	if cr.Spec.ReplicasFromNodeCount {
+		count, err := r.nodeCount(ctx, cr)
+		if err != nil {
+			return 0, err
+		}
+		base, source = count, "the node count"
+	}
+
+	key := appsv1alpha1.ReplicasOverrideAnnotation
+	value, ok := cr.Annotations[key]
+	if !ok {
+		key = appsv1alpha1.DeprecatedReplicasOverrideAnnotation
+		if value, ok = cr.Annotations[key]; !ok {
+			return base, nil
+		}
+	}
+	replicas, err := strconv.ParseInt(strings.TrimSpace(value), 10, 32)
+	if err != nil || replicas < 0 {
+		r.Recorder.Eventf(cr, corev1.EventTypeWarning, "InvalidReplicasOverride",
+			"Ignoring annotation %s=%q: expected a non-negative integer; using %s (%d)",
+			key, value, source, base)
+		return base, nil
+	}
+	return int32(replicas), nil
+}
+
+// managesReplicas reports whether the controller keeps the replica count of an existing Deployment
+// in sync. It doesn't when a HorizontalPodAutoscaler or spec.allowManualScaling hands it to others.
+func managesReplicas(cr *appsv1alpha1.SimpleApp) bool {
+	return cr.Spec.Autoscaling == nil && !cr.Spec.AllowManualScaling
+}
+
+// progressDeadlineSeconds returns the rollout progress deadline, spelling out the API server
+// default of 600s so an unset field doesn't look like drift.
+func progressDeadlineSeconds(cr *appsv1alpha1.SimpleApp) *int32 {
+	if cr.Spec.ProgressDeadlineSeconds == nil {
+		return ptr.To(int32(600))
+	}
+	return ptr.To(*cr.Spec.ProgressDeadlineSeconds)
+}
+
+// terminationGracePeriod returns the pod grace period, spelling out the API server default
+// so an unset field doesn't look like drift on every reconcile.
+func terminationGracePeriod(cr *appsv1alpha1.SimpleApp) *int64 {
+	if cr.Spec.TerminationGracePeriodSeconds == nil {
+		return ptr.To(int64(corev1.DefaultTerminationGracePeriodSeconds))
+	}
+	return ptr.To(*cr.Spec.TerminationGracePeriodSeconds)
+}
+
+// containerLifecycle translates spec.lifecycle, and the deprecated spec.preStop, into the container
+// lifecycle.
+func containerLifecycle(cr *appsv1alpha1.SimpleApp) *corev1.Lifecycle {
+	preStop := cr.Spec.PreStop
+	var postStart *appsv1alpha1.LifecycleHook
+	if l := cr.Spec.Lifecycle; l != nil {
+		postStart = l.PostStart
+		if l.PreStop != nil {
+			preStop = l.PreStop
+		}
+	}
+	if postStart == nil && preStop == nil {
+		return nil
+	}
+	return &corev1.Lifecycle{PostStart: lifecycleHandler(postStart), PreStop: lifecycleHandler(preStop)}
+}
+
+// lifecycleHandler translates a lifecycle hook of the SimpleApp, nil when it is unset.
+func lifecycleHandler(hook *appsv1alpha1.LifecycleHook) *corev1.LifecycleHandler {
+	if hook == nil {
+		return nil
+	}
+	handler := &corev1.LifecycleHandler{
+		Exec:    hook.Exec.DeepCopy(),
+		HTTPGet: hook.HTTPGet.DeepCopy(),
+	}
+	// Mirror the API server default so the Deployment comparison stays stable
+	if handler.HTTPGet != nil && handler.HTTPGet.Scheme == "" {
+		handler.HTTPGet.Scheme = corev1.URISchemeHTTP
+	}
+	return handler
+}
+
+// dnsPolicy returns spec.dnsPolicy, ClusterFirst unless set. Mirrors the API server default so the
+// Deployment comparison stays stable.
+func dnsPolicy(cr *appsv1alpha1.SimpleApp) corev1.DNSPolicy {
+	if cr.Spec.DNSPolicy == "" {
+		return corev1.DNSClusterFirst
+	}
+	return cr.Spec.DNSPolicy
+}
+
+// gpuResourceName is the extended resource spec.gpus stands for.
+const gpuResourceName corev1.ResourceName = "nvidia.com/gpu"
+
+// gpuResources returns the resources of the application container: a limit of spec.gpus GPUs.
+func gpuResources(cr *appsv1alpha1.SimpleApp) corev1.ResourceRequirements {
+	if cr.Spec.GPUs <= 0 {
+		return corev1.ResourceRequirements{}
+	}
+	return corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{gpuResourceName: *resource.NewQuantity(int64(cr.Spec.GPUs), resource.DecimalSI)},
+	}
+}
+
+// syncGPUs copies the GPU limit from desired onto existing, leaving other resources alone. The
+// API server defaults the request of an extended resource to its limit, and one may not be set
+// without the other, so the request is replaced too.
+func syncGPUs(existing *corev1.ResourceRequirements, desired corev1.ResourceRequirements) {
+	limit, ok := desired.Limits[gpuResourceName]
+	if !ok {
+		delete(existing.Limits, gpuResourceName)
+		delete(existing.Requests, gpuResourceName)
+		return
+	}
+	if existing.Limits == nil {
+		existing.Limits = corev1.ResourceList{}
+	}
+	existing.Limits[gpuResourceName] = limit
+	if existing.Requests != nil {
+		existing.Requests[gpuResourceName] = limit
+	}
+}
+
+// imagePullPolicy returns spec.imagePullPolicy, IfNotPresent unless set.
+func imagePullPolicy(cr *appsv1alpha1.SimpleApp) corev1.PullPolicy {
+	if cr.Spec.ImagePullPolicy == "" {
+		return corev1.PullIfNotPresent
+	}
+	return cr.Spec.ImagePullPolicy
+}
+
+// defaultedContainers returns user-supplied containers (init containers, sidecars) with the API
+// server defaults of the commonly omitted fields filled in, so comparing them with the live
+// Deployment stays stable.
+func defaultedContainers(in []corev1.Container) []corev1.Container {
+	if len(in) == 0 {
+		return nil
+	}
+	containers := make([]corev1.Container, len(in))
+	for i := range in {
+		c := in[i].DeepCopy()
+		if c.TerminationMessagePath == "" {
+			c.TerminationMessagePath = corev1.TerminationMessagePathDefault
+		}
+		if c.TerminationMessagePolicy == "" {
+			c.TerminationMessagePolicy = corev1.TerminationMessageReadFile
+		}
+		if c.ImagePullPolicy == "" {
+			c.ImagePullPolicy = defaultPullPolicy(c.Image)
+		}
+		for j := range c.Ports {
+			if c.Ports[j].Protocol == "" {
+				c.Ports[j].Protocol = corev1.ProtocolTCP
+			}
+		}
+		containers[i] = *c
+	}
+	return containers
+}
+
+// defaultPullPolicy mirrors the API server: images without a tag or tagged latest are always pulled.
+func defaultPullPolicy(image string) corev1.PullPolicy {
+	if strings.Contains(image, "@") {
+		return corev1.PullIfNotPresent
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	if i := strings.LastIndex(name, ":"); i >= 0 && name[i+1:] != "latest" {
+		return corev1.PullIfNotPresent
+	}
+	return corev1.PullAlways
+}
+
+// podSecurityContext returns spec.podSecurityContext. The API server turns an omitted
+// pod security context into an empty one, so that is mirrored here.
+func podSecurityContext(cr *appsv1alpha1.SimpleApp) *corev1.PodSecurityContext {
+	if cr.Spec.PodSecurityContext == nil {
+		return &corev1.PodSecurityContext{}
+	}
+	return cr.Spec.PodSecurityContext
+}
+
+// startupProbe returns spec.startupProbe with the API server defaults filled in,
+// so the Deployment comparison stays stable.
+func startupProbe(cr *appsv1alpha1.SimpleApp) *corev1.Probe {
+	if cr.Spec.StartupProbe == nil {
+		return nil
+	}
+	probe := cr.Spec.StartupProbe.DeepCopy()
+	if probe.TimeoutSeconds == 0 {
+		probe.TimeoutSeconds = 1
+	}
+	if probe.PeriodSeconds == 0 {
+		probe.PeriodSeconds = 10
+	}
+	if probe.SuccessThreshold == 0 {
+		probe.SuccessThreshold = 1
+	}
+	if probe.FailureThreshold == 0 {
+		probe.FailureThreshold = 3
+	}
+	if probe.HTTPGet != nil && probe.HTTPGet.Scheme == "" {
+		probe.HTTPGet.Scheme = corev1.URISchemeHTTP
+	}
+	if probe.GRPC != nil && probe.GRPC.Service == nil {
+		probe.GRPC.Service = ptr.To("")
+	}
+	return probe
+}
+
+// warnMissingReferences lists the referenced ConfigMaps, PersistentVolumeClaims and Secrets that don't
+// exist yet in the ReferencesMissing condition, set on cr in memory for the status written at the end
+// of the reconcile. A Warning event is only emitted for a reference the condition didn't list yet, so
+// resyncs don't repeat them while a reference deleted later is still reported.
+func (r *SimpleAppReconciler) warnMissingReferences(ctx context.Context, cr *appsv1alpha1.SimpleApp) error {
+	var reported string
+	if c := meta.FindStatusCondition(cr.Status.Conditions, appsv1alpha1.ConditionReferencesMissing); c != nil {
+		reported = c.Message
+	}
+	var missing []string
+	report := func(ref, reason, message string) {
+		missing = append(missing, ref)
+		if !strings.Contains(reported, ref) {
+			r.Recorder.Event(cr, corev1.EventTypeWarning, reason, message)
+		}
+	}
+
+	for _, v := range cr.Spec.Volumes {
+		switch {
+		case v.ConfigMap != "":
+			var cm corev1.ConfigMap
+			err := r.Get(ctx, client.ObjectKey{Name: v.ConfigMap, Namespace: cr.Namespace}, &cm)
+			if client.IgnoreNotFound(err) != nil {
+				return err
+			}
+			if err != nil {
+				report(fmt.Sprintf("ConfigMap %q", v.ConfigMap), "ConfigMapNotFound",
+					fmt.Sprintf("ConfigMap %q referenced by volume %q does not exist", v.ConfigMap, v.Name))
+			}
+		case v.PersistentVolumeClaim != "":
+			var pvc corev1.PersistentVolumeClaim
+			err := r.Get(ctx, client.ObjectKey{Name: v.PersistentVolumeClaim, Namespace: cr.Namespace}, &pvc)
+			if client.IgnoreNotFound(err) != nil {
+				return err
+			}
+			if err != nil {
+				report(fmt.Sprintf("PersistentVolumeClaim %q", v.PersistentVolumeClaim), "PersistentVolumeClaimNotFound",
+					fmt.Sprintf("PersistentVolumeClaim %q referenced by volume %q does not exist", v.PersistentVolumeClaim, v.Name))
+			}
+		}
+	}
+
+	for _, name := range cr.Spec.EnvFromSecret {
+		// Only fetch metadata so Secret payloads never end up in the controller's cache
+		secret := &metav1.PartialObjectMetadata{}
+		secret.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Secret"))
+		err := r.Get(ctx, client.ObjectKey{Name: name, Namespace: cr.Namespace}, secret)
+		if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		if err != nil {
+			report(fmt.Sprintf("Secret %q", name), "SecretNotFound",
+				fmt.Sprintf("Secret %q referenced by envFromSecret does not exist", name))
+		}
+	}
+
+	if len(missing) == 0 {
+		meta.RemoveStatusCondition(&cr.Status.Conditions, appsv1alpha1.ConditionReferencesMissing)
+		return nil
+	}
+	meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+		Type:               appsv1alpha1.ConditionReferencesMissing,
+		Status:             metav1.ConditionTrue,
+		Reason:             "NotFound",
+		Message:            strings.Join(missing, ", ") + " not found; pods wait until they exist",
+		ObservedGeneration: cr.Generation,
+	})
+	return nil
+}
+
+// ensureService creates or updates the Service to expose the application.
+// With spec.exposeService=false it removes the Service instead and returns nil. With
+// spec.externalService it removes the generated Service and returns the external one, nil if missing.
+func (r *SimpleAppReconciler) ensureService(ctx context.Context, cr *appsv1alpha1.SimpleApp, name string) (*corev1.Service, error) {
+	log := logf.FromContext(ctx).WithValues("Service", name, "Namespace", cr.Namespace)
+	if r.DisableServiceManagement {
+		if !exposesService(cr) {
+			return nil, nil
+		}
+		var existing corev1.Service
+		err := r.Get(ctx, client.ObjectKey{Name: serviceName(cr, name), Namespace: cr.Namespace}, &existing)
+		if err != nil {
+			return nil, client.IgnoreNotFound(err)
+		}
+		return &existing, nil
+	}
+	if !exposesService(cr) || cr.Spec.ExternalService != "" {
+		var existing corev1.Service
+		err := r.Get(ctx, client.ObjectKey{Name: name, Namespace: cr.Namespace}, &existing)
+		if err == nil && metav1.IsControlledBy(&existing, cr) {
+			reason := "exposeService is false"
+			if cr.Spec.RunOnce {
+				reason = "runOnce is set"
+			} else if exposesService(cr) {
+				reason = "externalService is set"
+			}
+			log.V(1).Info("Deleting Service", "Reason", reason)
+			if err = r.Delete(ctx, &existing); err == nil {
+				metrics.RecordChildOperation("Service", metrics.OperationDelete)
+			}
+		}
+		if client.IgnoreNotFound(err) != nil || !exposesService(cr) {
+			return nil, client.IgnoreNotFound(err)
+		}
+		return r.checkExternalService(ctx, cr)
+	}
+
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: cr.Namespace,
+			// Lets ServiceMonitors select the Service
+			Labels: r.childLabels(cr),
+		},
+		Spec: corev1.ServiceSpec{
+			Selector:              appLabels(cr),
+			Ports:                 r.servicePorts(cr),
+			Type:                  serviceType(cr),
+			ExternalTrafficPolicy: externalTrafficPolicy(cr),
+			IPFamilyPolicy:        ipFamilyPolicy(cr),
+			SessionAffinity:       sessionAffinity(cr),
+			SessionAffinityConfig: sessionAffinityConfig(cr),
+			// Only set on headless Services, which the webhook enforces
+			PublishNotReadyAddresses: ptr.Deref(cr.Spec.PublishNotReadyAddresses, false),
+		},
+	}
+	if cr.Spec.Headless {
+		svc.Spec.ClusterIP = corev1.ClusterIPNone
+	}
+	// The labels are hashed along with the spec, since a matching hash skips the Service entirely
+	metav1.SetMetaDataAnnotation(&svc.ObjectMeta, specHashAnnotation, specHash(struct {
+		Labels map[string]string
+		Spec   *corev1.ServiceSpec
+	}{svc.Labels, &svc.Spec}))
+
+	if err := ctrl.SetControllerReference(cr, svc, r.Scheme); err != nil {
+		return nil, err
+	}
+
+	var existing corev1.Service
+	err := r.getChild(ctx, client.ObjectKey{Name: svc.Name, Namespace: svc.Namespace}, &existing)
+	if err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return nil, err
+		}
+		log.V(1).Info("Creating Service")
+		if err := r.Create(ctx, svc); err != nil {
+			return nil, err
+		}
+		metrics.RecordChildOperation("Service", metrics.OperationCreate)
+		r.warnSingleStack(cr, svc)
+		return svc, nil
+	}
+
+	// The hash covers the whole desired spec, headless included, so a match skips the Update unless
+	// the labels, selector, ports or type were edited by hand
+	if appliedSpecHash(&existing) == svc.Annotations[specHashAnnotation] && metav1.IsControlledBy(&existing, cr) &&
+		hasLabels(existing.Labels, svc.Labels) &&
+		equality.Semantic.DeepEqual(existing.Spec.Selector, svc.Spec.Selector) &&
+		servicePortsMatch(existing.Spec.Ports, svc.Spec.Ports) &&
+		existing.Spec.Type == svc.Spec.Type {
+		log.V(1).Info("Service up to date")
+		return &existing, nil
+	}
+
+	// The cluster IP is immutable, so switching to or from headless needs a new Service
+	if (existing.Spec.ClusterIP == corev1.ClusterIPNone) != cr.Spec.Headless {
+		log.V(1).Info("Recreating Service", "Reason", "headless changed")
+		return r.recreateService(ctx, cr, &existing, svc)
+	}
+
+	// The desired spec moved on, or the Service is being adopted; the comparisons below only
+	// write the fields that differ
+	var changed []string
+	adopted, err := r.adoptChild(&existing, cr)
+	if err != nil {
+		return nil, err
+	}
+	if adopted {
+		changed = append(changed, "ownerReference")
+	}
+	if appliedSpecHash(&existing) != svc.Annotations[specHashAnnotation] {
+		changed = append(changed, "specHash")
+	}
+	setSpecHash(&existing, svc.Annotations[specHashAnnotation])
+	if existing.Spec.Type != svc.Spec.Type {
+		existing.Spec.Type = svc.Spec.Type
+		changed = append(changed, "type")
+	}
+	if existing.Spec.ExternalTrafficPolicy != svc.Spec.ExternalTrafficPolicy {
+		existing.Spec.ExternalTrafficPolicy = svc.Spec.ExternalTrafficPolicy
+		changed = append(changed, "externalTrafficPolicy")
+	}
+	if !servicePortsMatch(existing.Spec.Ports, svc.Spec.Ports) ||
+		!equality.Semantic.DeepEqual(existing.Spec.Selector, svc.Spec.Selector) ||
+		existing.Labels["app"] != svc.Labels["app"] {
+		// Port names and numbers are replaced together; an update the API server rejects, e.g. a
+		// node port conflict, recreates the Service below
+		existing.Spec.Ports = keepNodePorts(existing.Spec.Ports, svc.Spec.Ports, svc.Spec.Type)
+		existing.Spec.Selector = svc.Spec.Selector
+		metav1.SetMetaDataLabel(&existing.ObjectMeta, "app", svc.Labels["app"])
+		changed = append(changed, "ports")
+	}
+	labelsChanged := false
+	for k, v := range svc.Labels {
+		if existing.Labels[k] != v {
+			metav1.SetMetaDataLabel(&existing.ObjectMeta, k, v)
+			labelsChanged = true
+		}
+	}
+	if labelsChanged {
+		changed = append(changed, "labels")
+	}
+	if existing.Spec.SessionAffinity != svc.Spec.SessionAffinity ||
+		!equality.Semantic.DeepEqual(existing.Spec.SessionAffinityConfig, svc.Spec.SessionAffinityConfig) {
+		existing.Spec.SessionAffinity = svc.Spec.SessionAffinity
+		existing.Spec.SessionAffinityConfig = svc.Spec.SessionAffinityConfig
+		changed = append(changed, "sessionAffinity")
+	}
+	if existing.Spec.PublishNotReadyAddresses != svc.Spec.PublishNotReadyAddresses {
+		existing.Spec.PublishNotReadyAddresses = svc.Spec.PublishNotReadyAddresses
+		changed = append(changed, "publishNotReadyAddresses")
+	}
+	policyChanged := !equality.Semantic.DeepEqual(existing.Spec.IPFamilyPolicy, svc.Spec.IPFamilyPolicy)
+	if policyChanged {
+		existing.Spec.IPFamilyPolicy = svc.Spec.IPFamilyPolicy
+		// Going back to a single stack releases the secondary family and its cluster IP
+		if *svc.Spec.IPFamilyPolicy == corev1.IPFamilyPolicySingleStack {
+			if len(existing.Spec.IPFamilies) > 1 {
+				existing.Spec.IPFamilies = existing.Spec.IPFamilies[:1]
+			}
+			if len(existing.Spec.ClusterIPs) > 1 {
+				existing.Spec.ClusterIPs = existing.Spec.ClusterIPs[:1]
+			}
+		}
+		changed = append(changed, "ipFamilyPolicy")
+	}
+
+	if len(changed) == 0 {
+		log.V(1).Info("Service up to date")
+	} else {
+		log.V(1).Info("Updating Service", "Changed", changed)
+		if err := r.Update(ctx, &existing); err != nil {
+			if apierrors.IsInvalid(err) {
+				log.Info("Service update rejected, recreating it", "reason", err.Error())
+				return r.recreateService(ctx, cr, &existing, svc)
+			}
+			return nil, err
+		}
+		metrics.RecordChildOperation("Service", metrics.OperationUpdate)
+		if policyChanged {
+			r.warnSingleStack(cr, &existing)
+		}
 	}
 
-	// 4. Ensure the Ingress exists and matches the desired state (Infrastructure agnostic)
-	_, err = r.ensureIngress(ctx, &simpleApp)
+	return &existing, nil
+}
+
+// checkExternalService looks up spec.externalService and warns when it is missing or its selector
+// doesn't match the app's pods, in which case it would route no traffic to them.
+func (r *SimpleAppReconciler) checkExternalService(ctx context.Context, cr *appsv1alpha1.SimpleApp) (*corev1.Service, error) {
+	var svc corev1.Service
+	err := r.Get(ctx, client.ObjectKey{Name: cr.Spec.ExternalService, Namespace: cr.Namespace}, &svc)
+	if apierrors.IsNotFound(err) {
+		r.Recorder.Eventf(cr, corev1.EventTypeWarning, "ExternalServiceNotFound",
+			"Service %q referenced by externalService does not exist", cr.Spec.ExternalService)
+		return nil, nil
+	}
 	if err != nil {
-		return ctrl.Result{}, err
+		return nil, err
 	}
 
-	// 5. Update CR Status with the current state of the Deployment
-	if simpleApp.Status.ReadyReplicas != deployment.Status.ReadyReplicas {
-		simpleApp.Status.ReadyReplicas = deployment.Status.ReadyReplicas
-		if err := r.Status().Update(ctx, &simpleApp); err != nil {
-			return ctrl.Result{}, err
+	labels := podLabels(cr)
+	selects := len(svc.Spec.Selector) > 0
+	for k, v := range svc.Spec.Selector {
+		if labels[k] != v {
+			selects = false
+			break
 		}
 	}
-
-	log.Info("Successfully reconciled SimpleApp", "Name", simpleApp.Name, "Image", simpleApp.Spec.Image)
-	return ctrl.Result{}, nil
+	if !selects {
+		r.Recorder.Eventf(cr, corev1.EventTypeWarning, "ExternalServiceMismatch",
+			"Service %q referenced by externalService does not select the app's pods (selector %v, pod labels %v)",
+			svc.Name, svc.Spec.Selector, labels)
+	}
+	return &svc, nil
 }
 
-// ensureDeployment creates or updates the Deployment based on the CR specs.
-func (r *SimpleAppReconciler) ensureDeployment(ctx context.Context, cr *appsv1alpha1.SimpleApp) (*appsv1.Deployment, error) {
-	desiredReplicas := cr.Spec.Replicas
-
-	dep := &appsv1.Deployment{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      cr.Name,
-			Namespace: cr.Namespace,
-		},
-		Spec: appsv1.DeploymentSpec{
-			Replicas: &desiredReplicas,
-			Selector: &metav1.LabelSelector{
-				MatchLabels: map[string]string{"app": cr.Name},
-			},
-			Template: corev1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{
-					Labels: map[string]string{"app": cr.Name},
-				},
-				Spec: corev1.PodSpec{
-					Containers: []corev1.Container{{
-						Name:            "app",
-						Image:           cr.Spec.Image,
-						ImagePullPolicy: corev1.PullIfNotPresent,
-						Ports: []corev1.ContainerPort{{
-							ContainerPort: cr.Spec.ContainerPort,
-						}},
-					}},
-				},
-			},
-		},
+// serviceName returns the name of the Service in front of the app: spec.externalService when set,
+// otherwise the generated one.
+func serviceName(cr *appsv1alpha1.SimpleApp, name string) string {
+	if cr.Spec.ExternalService != "" {
+		return cr.Spec.ExternalService
 	}
+	return name
+}
 
-	if err := ctrl.SetControllerReference(cr, dep, r.Scheme); err != nil {
+// recreateService replaces a Service whose changes can't be applied in place.
+func (r *SimpleAppReconciler) recreateService(ctx context.Context, cr *appsv1alpha1.SimpleApp, existing, desired *corev1.Service) (*corev1.Service, error) {
+	if err := r.Delete(ctx, existing); client.IgnoreNotFound(err) != nil {
+		return nil, err
+	}
+	metrics.RecordChildOperation("Service", metrics.OperationDelete)
+	if err := r.Create(ctx, desired); err != nil {
 		return nil, err
 	}
+	metrics.RecordChildOperation("Service", metrics.OperationCreate)
+	r.Recorder.Eventf(cr, corev1.EventTypeNormal, "ServiceRecreated",
+		"Service %s was recreated because the change could not be applied in place", desired.Name)
+	r.warnSingleStack(cr, desired)
+	return desired, nil
+}
 
-	var existing appsv1.Deployment
-	err := r.Get(ctx, client.ObjectKey{Name: dep.Name, Namespace: dep.Namespace}, &existing)
-	if err != nil {
-		if client.IgnoreNotFound(err) != nil {
-			return nil, err
-		}
-		if err := r.Create(ctx, dep); err != nil {
-			return nil, err
-		}
-		return dep, nil
+// serviceType returns the type of the Service, ClusterIP unless spec.serviceType says otherwise.
+func serviceType(cr *appsv1alpha1.SimpleApp) corev1.ServiceType {
+	if cr.Spec.ServiceType == "" {
+		return corev1.ServiceTypeClusterIP
 	}
+	return cr.Spec.ServiceType
+}
 
-	needsUpdate := false
-	if *existing.Spec.Replicas != *dep.Spec.Replicas {
-		needsUpdate = true
+// externalTrafficPolicy returns the external traffic policy of NodePort and LoadBalancer Services,
+// defaulting to Cluster like the API server. Other types don't support it and get none.
+func externalTrafficPolicy(cr *appsv1alpha1.SimpleApp) corev1.ServiceExternalTrafficPolicy {
+	switch serviceType(cr) {
+	case corev1.ServiceTypeNodePort, corev1.ServiceTypeLoadBalancer:
+		if cr.Spec.ExternalTrafficPolicy == "" {
+			return corev1.ServiceExternalTrafficPolicyCluster
+		}
+		return cr.Spec.ExternalTrafficPolicy
 	}
-	if existing.Spec.Template.Spec.Containers[0].Image != dep.Spec.Template.Spec.Containers[0].Image {
-		needsUpdate = true
+	return ""
+}
+
+// podSubdomain gives pods a DNS name under a headless Service (<pod>.<service>.<namespace>.svc).
+func podSubdomain(cr *appsv1alpha1.SimpleApp, name string) string {
+	if cr.Spec.Headless && exposesService(cr) {
+		return name
 	}
+	return ""
+}
 
-	if needsUpdate {
-		existing.Spec.Replicas = dep.Spec.Replicas
-		existing.Spec.Template.Spec.Containers[0].Image = dep.Spec.Template.Spec.Containers[0].Image
-		if err := r.Update(ctx, &existing); err != nil {
-			return nil, err
-		}
+// exposesService reports whether the SimpleApp gets a Service, which is the default
+// except for run-once apps.
+func exposesService(cr *appsv1alpha1.SimpleApp) bool {
+	return !cr.Spec.RunOnce && (cr.Spec.ExposeService == nil || *cr.Spec.ExposeService)
+}
+
+// ipFamilyPolicy maps spec.dualStack to the Service IP family policy. SingleStack is the
+// API server default, so it is spelled out to keep the comparison stable.
+func ipFamilyPolicy(cr *appsv1alpha1.SimpleApp) *corev1.IPFamilyPolicy {
+	if cr.Spec.DualStack {
+		return ptr.To(corev1.IPFamilyPolicyPreferDualStack)
 	}
+	return ptr.To(corev1.IPFamilyPolicySingleStack)
+}
 
-	return &existing, nil
+// sessionAffinity returns the session affinity of the Service. None is the API server default,
+// so it is spelled out to keep the comparison stable.
+func sessionAffinity(cr *appsv1alpha1.SimpleApp) corev1.ServiceAffinity {
+	if cr.Spec.SessionAffinity == "" {
+		return corev1.ServiceAffinityNone
+	}
+	return cr.Spec.SessionAffinity
 }
 
-// ensureService creates or updates the Service to expose the application.
-func (r *SimpleAppReconciler) ensureService(ctx context.Context, cr *appsv1alpha1.SimpleApp) (*corev1.Service, error) {
-	svc := &corev1.Service{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      cr.Name,
-			Namespace: cr.Namespace,
-		},
-		Spec: corev1.ServiceSpec{
-			Selector: map[string]string{"app": cr.Name},
-			Ports: []corev1.ServicePort{{
-				Port:       cr.Spec.ServicePort,
-				TargetPort: intstr.FromInt(int(cr.Spec.ContainerPort)),
-			}},
-			Type: corev1.ServiceTypeClusterIP,
-		},
+// sessionAffinityConfig returns the ClientIP timeout the API server would default, nil without
+// ClientIP affinity.
+func sessionAffinityConfig(cr *appsv1alpha1.SimpleApp) *corev1.SessionAffinityConfig {
+	if sessionAffinity(cr) != corev1.ServiceAffinityClientIP {
+		return nil
 	}
+	timeout := cr.Spec.SessionAffinityTimeoutSeconds
+	if timeout == nil {
+		timeout = ptr.To(corev1.DefaultClientIPServiceAffinitySeconds)
+	}
+	return &corev1.SessionAffinityConfig{ClientIP: &corev1.ClientIPConfig{TimeoutSeconds: timeout}}
+}
 
-	if err := ctrl.SetControllerReference(cr, svc, r.Scheme); err != nil {
-		return nil, err
+// warnSingleStack emits a Warning event when dual-stack was requested but the API server only
+// assigned one IP family to the Service, i.e. the cluster is not configured for dual-stack.
+func (r *SimpleAppReconciler) warnSingleStack(cr *appsv1alpha1.SimpleApp, svc *corev1.Service) {
+	if cr.Spec.DualStack && len(svc.Spec.IPFamilies) == 1 {
+		r.Recorder.Eventf(cr, corev1.EventTypeWarning, "DualStackUnavailable",
+			"Dual-stack was requested but the Service only got %s; the cluster does not seem to support dual-stack",
+			svc.Spec.IPFamilies[0])
 	}
+}
 
-	var existing corev1.Service
-	err := r.Get(ctx, client.ObjectKey{Name: svc.Name, Namespace: svc.Namespace}, &existing)
-	if err != nil {
-		if client.IgnoreNotFound(err) != nil {
-			return nil, err
+// servicePortsMatch compares the fields of the Service ports set by the controller,
+// ignoring the ones defaulted by the API server.
+func servicePortsMatch(existing, desired []corev1.ServicePort) bool {
+	if len(existing) != len(desired) {
+		return false
+	}
+	for i := range desired {
+		if existing[i].Name != desired[i].Name ||
+			existing[i].Port != desired[i].Port ||
+			existing[i].TargetPort != desired[i].TargetPort {
+			return false
 		}
-		if err := r.Create(ctx, svc); err != nil {
-			return nil, err
+	}
+	return true
+}
+
+// hasLabels reports whether labels has every key of want with the same value.
+func hasLabels(labels, want map[string]string) bool {
+	for k, v := range want {
+		if labels[k] != v {
+			return false
 		}
-		return svc, nil
 	}
+	return true
+}
 
-	if existing.Spec.Ports[0].Port != svc.Spec.Ports[0].Port ||
-		existing.Spec.Ports[0].TargetPort != svc.Spec.Ports[0].TargetPort {
-		existing.Spec.Ports = svc.Spec.Ports
-		if err := r.Update(ctx, &existing); err != nil {
-			return nil, err
+// keepNodePorts returns the desired ports with the node ports already allocated to the existing
+// ports of the same number and protocol, so renaming a port of a NodePort or LoadBalancer Service
+// doesn't move it to a new node port.
+func keepNodePorts(existing, desired []corev1.ServicePort, serviceType corev1.ServiceType) []corev1.ServicePort {
+	ports := append([]corev1.ServicePort(nil), desired...)
+	if serviceType != corev1.ServiceTypeNodePort && serviceType != corev1.ServiceTypeLoadBalancer {
+		return ports
+	}
+	for i := range ports {
+		for _, e := range existing {
+			if e.Port == ports[i].Port && protocol(e) == protocol(ports[i]) {
+				ports[i].NodePort = e.NodePort
+				break
+			}
 		}
 	}
+	return ports
+}
 
-	return &existing, nil
+// protocol returns the protocol of a Service port, spelling out the API server default.
+func protocol(port corev1.ServicePort) corev1.Protocol {
+	if port.Protocol == "" {
+		return corev1.ProtocolTCP
+	}
+	return port.Protocol
+}
+
+// servicePort returns the port exposed by the Service. The defaulting webhook normally fills it
+// in; fall back to the same default for objects that bypassed it.
+func (r *SimpleAppReconciler) servicePort(cr *appsv1alpha1.SimpleApp) int32 {
+	switch {
+	case cr.Spec.ServicePort != 0:
+		return cr.Spec.ServicePort
+	case r.ServicePortFollowsContainerPort:
+		return cr.Spec.ContainerPort
+	}
+	return appsv1alpha1.DefaultServicePort
 }
 
 // ensureIngress manages the Ingress creation based on the environment variable INGRESS_CLASS_NAME.
-func (r *SimpleAppReconciler) ensureIngress(ctx context.Context, cr *appsv1alpha1.SimpleApp) (*networkingv1.Ingress, error) {
+func (r *SimpleAppReconciler) ensureIngress(ctx context.Context, cr *appsv1alpha1.SimpleApp, name string) (*networkingv1.Ingress, error) {
 	// Retrieve the Ingress class name from the environment variable injected by Kustomize
 	ingressClassName := os.Getenv("INGRESS_CLASS_NAME")
 
@@ -209,12 +1547,25 @@ func (r *SimpleAppReconciler) ensureIngress(ctx context.Context, cr *appsv1alpha
 		return nil, nil
 	}
 
+	// Without a Service there is no backend to route to
+	if !exposesService(cr) {
+		var existing networkingv1.Ingress
+		err := r.Get(ctx, client.ObjectKey{Name: name + "-ingress", Namespace: cr.Namespace}, &existing)
+		if err == nil && metav1.IsControlledBy(&existing, cr) {
+			if err = r.Delete(ctx, &existing); err == nil {
+				metrics.RecordChildOperation("Ingress", metrics.OperationDelete)
+			}
+		}
+		return nil, client.IgnoreNotFound(err)
+	}
+
 	pathType := networkingv1.PathTypePrefix
 
 	ingress := &networkingv1.Ingress{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      cr.Name + "-ingress",
+			Name:      name + "-ingress",
 			Namespace: cr.Namespace,
+			Labels:    r.childLabels(cr),
 			Annotations: map[string]string{
 				// Legacy annotation for compatibility
 				"kubernetes.io/ingress.class": ingressClassName,
@@ -235,9 +1586,9 @@ func (r *SimpleAppReconciler) ensureIngress(ctx context.Context, cr *appsv1alpha
 									PathType: &pathType,
 									Backend: networkingv1.IngressBackend{
 										Service: &networkingv1.IngressServiceBackend{
-											Name: cr.Name,
+											Name: serviceName(cr, name),
 											Port: networkingv1.ServiceBackendPort{
-												Number: cr.Spec.ServicePort,
+												Number: r.servicePort(cr),
 											},
 										},
 									},
@@ -257,7 +1608,7 @@ func (r *SimpleAppReconciler) ensureIngress(ctx context.Context, cr *appsv1alpha
 
 	// Check if Ingress already exists
 	var existing networkingv1.Ingress
-	err := r.Get(ctx, client.ObjectKey{Name: ingress.Name, Namespace: ingress.Namespace}, &existing)
+	err := r.getChild(ctx, client.ObjectKey{Name: ingress.Name, Namespace: ingress.Namespace}, &existing)
 	if err != nil {
 		if client.IgnoreNotFound(err) != nil {
 			return nil, err
@@ -266,26 +1617,576 @@ func (r *SimpleAppReconciler) ensureIngress(ctx context.Context, cr *appsv1alpha
 		if err := r.Create(ctx, ingress); err != nil {
 			return nil, err
 		}
+		metrics.RecordChildOperation("Ingress", metrics.OperationCreate)
 		return ingress, nil
 	}
 
-	// Update Logic: If the Ingress class has changed, update the resource
-	if existing.Spec.IngressClassName != nil && *existing.Spec.IngressClassName != ingressClassName {
+	// Update Logic: If the Ingress class or the backend Service has changed, update the resource
+	if (existing.Spec.IngressClassName != nil && *existing.Spec.IngressClassName != ingressClassName) ||
+		!equality.Semantic.DeepEqual(existing.Spec.Rules, ingress.Spec.Rules) ||
+		!hasLabels(existing.Labels, ingress.Labels) {
 		existing.Spec.IngressClassName = &ingressClassName
+		existing.Spec.Rules = ingress.Spec.Rules
+		for k, v := range ingress.Labels {
+			metav1.SetMetaDataLabel(&existing.ObjectMeta, k, v)
+		}
 		if err := r.Update(ctx, &existing); err != nil {
 			return nil, err
 		}
+		metrics.RecordChildOperation("Ingress", metrics.OperationUpdate)
 	}
 
 	return &existing, nil
 }
 
+// ensurePDB creates or updates the PodDisruptionBudget covering the app's pods,
+// and deletes it once spec.podDisruptionBudget is removed.
+func (r *SimpleAppReconciler) ensurePDB(ctx context.Context, cr *appsv1alpha1.SimpleApp, name string, replicas int32) error {
+	var existing policyv1.PodDisruptionBudget
+	err := r.Get(ctx, client.ObjectKey{Name: name, Namespace: cr.Namespace}, &existing)
+	if client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	found := err == nil
+
+	if cr.Spec.PodDisruptionBudget == nil {
+		if found && metav1.IsControlledBy(&existing, cr) {
+			if err := r.Delete(ctx, &existing); client.IgnoreNotFound(err) != nil {
+				return err
+			}
+			metrics.RecordChildOperation("PodDisruptionBudget", metrics.OperationDelete)
+		}
+		return nil
+	}
+
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: cr.Namespace,
+			Labels:    r.childLabels(cr),
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MinAvailable:   cr.Spec.PodDisruptionBudget.MinAvailable,
+			MaxUnavailable: cr.Spec.PodDisruptionBudget.MaxUnavailable,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: appLabels(cr),
+			},
+		},
+	}
+	if err := ctrl.SetControllerReference(cr, pdb, r.Scheme); err != nil {
+		return err
+	}
+
+	if !found {
+		if found, err = r.childExists(ctx, client.ObjectKeyFromObject(pdb), &existing); err != nil {
+			return err
+		}
+	}
+	if !found {
+		r.warnBlockedDisruptions(cr, replicas)
+		if err := r.Create(ctx, pdb); err != nil {
+			return err
+		}
+		metrics.RecordChildOperation("PodDisruptionBudget", metrics.OperationCreate)
+		return nil
+	}
+
+	if !equality.Semantic.DeepEqual(existing.Spec.MinAvailable, pdb.Spec.MinAvailable) ||
+		!equality.Semantic.DeepEqual(existing.Spec.MaxUnavailable, pdb.Spec.MaxUnavailable) ||
+		!equality.Semantic.DeepEqual(existing.Spec.Selector, pdb.Spec.Selector) ||
+		!hasLabels(existing.Labels, pdb.Labels) {
+		existing.Spec.MinAvailable = pdb.Spec.MinAvailable
+		existing.Spec.MaxUnavailable = pdb.Spec.MaxUnavailable
+		existing.Spec.Selector = pdb.Spec.Selector
+		for k, v := range pdb.Labels {
+			metav1.SetMetaDataLabel(&existing.ObjectMeta, k, v)
+		}
+		r.warnBlockedDisruptions(cr, replicas)
+		if err := r.Update(ctx, &existing); err != nil {
+			return err
+		}
+		metrics.RecordChildOperation("PodDisruptionBudget", metrics.OperationUpdate)
+	}
+	return nil
+}
+
+// warnBlockedDisruptions emits a Warning event when the PodDisruptionBudget being written
+// would not allow any voluntary disruption at the given replica count.
+func (r *SimpleAppReconciler) warnBlockedDisruptions(cr *appsv1alpha1.SimpleApp, replicas int32) {
+	if !cr.Spec.PodDisruptionBudget.BlocksDisruptions(replicas) {
+		return
+	}
+	r.Recorder.Eventf(cr, corev1.EventTypeWarning, "PodDisruptionBudgetBlocksDisruptions",
+		"The PodDisruptionBudget allows no pod of the %d replicas to be evicted, so node drains will hang; "+
+			"increase replicas or lower minAvailable / raise maxUnavailable", replicas)
+}
+
+// progressDeadlineExceededReason is the reason the Deployment controller gives for a rollout
+// that made no progress within its deadline.
+const progressDeadlineExceededReason = "ProgressDeadlineExceeded"
+
+// progressDeadlineExceeded returns the Progressing condition of a Deployment whose rollout
+// exceeded its progress deadline, or nil.
+func progressDeadlineExceeded(dep *appsv1.Deployment) *appsv1.DeploymentCondition {
+	for i, c := range dep.Status.Conditions {
+		if c.Type == appsv1.DeploymentProgressing && c.Status == corev1.ConditionFalse &&
+			c.Reason == progressDeadlineExceededReason {
+			return &dep.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// readyCondition reports whether all desired replicas of the Deployment are ready.
+func readyCondition(cr *appsv1alpha1.SimpleApp, dep *appsv1.Deployment) metav1.Condition {
+	replicas := desiredReplicas(cr, dep)
+	message := fmt.Sprintf("%d/%d replicas ready", dep.Status.ReadyReplicas, replicas)
+	if stuck := progressDeadlineExceeded(dep); stuck != nil {
+		return metav1.Condition{
+			Type:               appsv1alpha1.ConditionReady,
+			Status:             metav1.ConditionFalse,
+			Reason:             progressDeadlineExceededReason,
+			Message:            message + ": " + stuck.Message,
+			ObservedGeneration: cr.Generation,
+		}
+	}
+	if replicas == 0 {
+		return metav1.Condition{
+			Type:               appsv1alpha1.ConditionReady,
+			Status:             metav1.ConditionTrue,
+			Reason:             "ScaledToZero",
+			Message:            scaledToZeroMessage,
+			ObservedGeneration: cr.Generation,
+		}
+	}
+	if dep.Status.ReadyReplicas >= replicas {
+		return metav1.Condition{
+			Type:               appsv1alpha1.ConditionReady,
+			Status:             metav1.ConditionTrue,
+			Reason:             "AllReplicasReady",
+			Message:            message,
+			ObservedGeneration: cr.Generation,
+		}
+	}
+	return metav1.Condition{
+		Type:               appsv1alpha1.ConditionReady,
+		Status:             metav1.ConditionFalse,
+		Reason:             "ReplicasNotReady",
+		Message:            message,
+		ObservedGeneration: cr.Generation,
+	}
+}
+
+// desiredReplicas follows the Deployment, whose replica count may be driven by an autoscaler.
+func desiredReplicas(cr *appsv1alpha1.SimpleApp, dep *appsv1.Deployment) int32 {
+	if dep.Spec.Replicas != nil {
+		return *dep.Spec.Replicas
+	}
+	return specReplicas(cr)
+}
+
+// specReplicas returns spec.replicas, spelling out its default of 1.
+func specReplicas(cr *appsv1alpha1.SimpleApp) int32 {
+	return ptr.Deref(cr.Spec.Replicas, 1)
+}
+
+// serviceDNS returns the in-cluster address of the Service in front of the app, "" without one.
+// Only the generated Service is known to listen on spec.servicePort, so the address of an external
+// Service has no port, and an ExternalName Service reports the name it points to.
+func (r *SimpleAppReconciler) serviceDNS(cr *appsv1alpha1.SimpleApp, svc *corev1.Service) string {
+	switch {
+	case svc == nil:
+		return ""
+	case cr.Spec.ExternalService == "":
+		return fmt.Sprintf("%s.%s.svc.%s:%d", svc.Name, cr.Namespace, clusterDomain, r.servicePort(cr))
+	case svc.Spec.Type == corev1.ServiceTypeExternalName:
+		return svc.Spec.ExternalName
+	default:
+		return fmt.Sprintf("%s.%s.svc.%s", svc.Name, cr.Namespace, clusterDomain)
+	}
+}
+
+// serviceEndpoint reports the addresses of the live Service, nil without one.
+func serviceEndpoint(svc *corev1.Service) *appsv1alpha1.ServiceEndpoint {
+	if svc == nil {
+		return nil
+	}
+	endpoint := &appsv1alpha1.ServiceEndpoint{ClusterIP: svc.Spec.ClusterIP}
+	if len(svc.Spec.Ports) > 0 {
+		endpoint.Port = svc.Spec.Ports[0].Port
+	}
+	if ingress := svc.Status.LoadBalancer.Ingress; len(ingress) > 0 {
+		endpoint.ExternalAddress = ingress[0].IP
+		if endpoint.ExternalAddress == "" {
+			endpoint.ExternalAddress = ingress[0].Hostname
+		}
+	}
+	return endpoint
+}
+
+// statusSummary renders the one-line summary shown by kubectl get, e.g.
+// "3/3 ready, ClusterIP 10.0.0.5:80, image nginx:1.25". It only uses settled values
+// (no timestamps or transient states) so it doesn't change on every reconcile.
+// svc is nil when the app isn't exposed.
+func (r *SimpleAppReconciler) statusSummary(cr *appsv1alpha1.SimpleApp, dep *appsv1.Deployment, svc *corev1.Service) string {
+	replicas := fmt.Sprintf("%d/%d ready", dep.Status.ReadyReplicas, desiredReplicas(cr, dep))
+	if desiredReplicas(cr, dep) == 0 {
+		replicas = "scaled to zero"
+	}
+	if svc == nil {
+		return fmt.Sprintf("%s, no Service, image %s", replicas, appImage(cr))
+	}
+	clusterIP := svc.Spec.ClusterIP
+	if clusterIP == "" {
+		clusterIP = "<pending>"
+	}
+	return fmt.Sprintf("%s, %s %s:%d, image %s", replicas, svc.Spec.Type, clusterIP, r.servicePort(cr), appImage(cr))
+}
+
+// runningImage returns the image of the app container in the Deployment's pod template, or "" if the
+// container is missing.
+func runningImage(cr *appsv1alpha1.SimpleApp, dep *appsv1.Deployment) string {
+	containers := dep.Spec.Template.Spec.Containers
+	if i := containerIndex(containers, containerName(cr)); i >= 0 {
+		return containers[i].Image
+	}
+	return ""
+}
+
+// changeCause describes the revision of the Deployment rolled out for the current SimpleApp spec.
+func changeCause(cr *appsv1alpha1.SimpleApp, image string) string {
+	return fmt.Sprintf("SimpleApp %s generation %d: image %s", cr.Name, cr.Generation, image)
+}
+
+// restartCountResync is how often an app whose containers restarted is reconciled again, since
+// restarts don't change the Deployment and so don't trigger a reconcile by themselves.
+const restartCountResync = time.Minute
+
+// totalRestarts sums the restart counts of the containers of the pods.
+func totalRestarts(pods []corev1.Pod) int32 {
+	var restarts int32
+	for i := range pods {
+		for _, status := range pods[i].Status.ContainerStatuses {
+			restarts += status.RestartCount
+		}
+	}
+	return restarts
+}
+
+// imageRolloutMessage is the status message of a SimpleApp rolling out a new image.
+func imageRolloutMessage(image string) string {
+	return "Rolling out image " + image
+}
+
+// imageRollingOut reports whether the Deployment is rolling out an image change: the image changed
+// since the last status update, or that update reported the rollout and it hasn't reached every
+// replica yet. cr.Status is the status before this reconcile.
+func imageRollingOut(cr *appsv1alpha1.SimpleApp, dep *appsv1.Deployment) bool {
+	image := runningImage(cr, dep)
+	if cr.Status.RunningImage != "" && cr.Status.RunningImage != image {
+		return true
+	}
+	rolledOut := dep.Status.ObservedGeneration >= dep.Generation &&
+		dep.Status.UpdatedReplicas >= desiredReplicas(cr, dep)
+	return cr.Status.Message == imageRolloutMessage(image) && !rolledOut
+}
+
+// notifyReadiness sends a rollout notification for a change of the Ready condition.
+func (r *SimpleAppReconciler) notifyReadiness(ctx context.Context, cr *appsv1alpha1.SimpleApp, ready metav1.Condition) {
+	if r.Notifier == nil {
+		return
+	}
+	event := appsv1alpha1.NotificationDegraded
+	if ready.Status == metav1.ConditionTrue {
+		event = appsv1alpha1.NotificationReady
+	}
+	if _, err := r.Notifier.Notify(ctx, cr, event, ready.Message); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to send rollout notification", "Event", event)
+		r.Recorder.Eventf(cr, corev1.EventTypeWarning, "NotificationFailed", "Failed to send %s notification: %v", event, err)
+	}
+}
+
+// selectorCollisionCondition reports whether the app selector matches pods that are not
+// managed by the SimpleApp's Deployment (i.e. not controlled by one of its ReplicaSets).
+func selectorCollisionCondition(cr *appsv1alpha1.SimpleApp, dep *appsv1.Deployment, foreign []string) metav1.Condition {
+	if len(foreign) == 0 {
+		return metav1.Condition{
+			Type:               appsv1alpha1.ConditionSelectorCollision,
+			Status:             metav1.ConditionFalse,
+			Reason:             "NoForeignPods",
+			Message:            "The selector only matches pods managed by this SimpleApp",
+			ObservedGeneration: cr.Generation,
+		}
+	}
+	return metav1.Condition{
+		Type:   appsv1alpha1.ConditionSelectorCollision,
+		Status: metav1.ConditionTrue,
+		Reason: "ForeignPodsSelected",
+		Message: fmt.Sprintf("Selector %v also matches %d pod(s) not managed by this SimpleApp: %s",
+			dep.Spec.Selector.MatchLabels, len(foreign), strings.Join(foreign, ", ")),
+		ObservedGeneration: cr.Generation,
+	}
+}
+
+// pausedCondition reports that spec.paused holds back the rollout of the Deployment.
+func pausedCondition(cr *appsv1alpha1.SimpleApp) metav1.Condition {
+	return metav1.Condition{
+		Type:               appsv1alpha1.ConditionPaused,
+		Status:             metav1.ConditionTrue,
+		Reason:             "RolloutPaused",
+		Message:            "Spec changes are applied to the Deployment but not rolled out until spec.paused is false",
+		ObservedGeneration: cr.Generation,
+	}
+}
+
+// appPods lists the pods matched by the Deployment selector, split into the pods of the
+// Deployment's ReplicaSets and the names of the other (foreign) pods, sorted. The pods of siblings,
+// other Deployments of the SimpleApp that the selector matches as well (i.e. the canary), are in
+// neither list; nil siblings are ignored.
+func (r *SimpleAppReconciler) appPods(ctx context.Context, cr *appsv1alpha1.SimpleApp, dep *appsv1.Deployment, siblings ...*appsv1.Deployment) ([]corev1.Pod, []string, error) {
+	selector := client.MatchingLabels(dep.Spec.Selector.MatchLabels)
+
+	var replicaSets appsv1.ReplicaSetList
+	if err := r.List(ctx, &replicaSets, client.InNamespace(cr.Namespace), selector); err != nil {
+		return nil, nil, err
+	}
+	owned := map[types.UID]bool{}
+	ofSibling := map[types.UID]bool{}
+	for i := range replicaSets.Items {
+		if metav1.IsControlledBy(&replicaSets.Items[i], dep) {
+			owned[replicaSets.Items[i].UID] = true
+		}
+		for _, sibling := range siblings {
+			if sibling != nil && metav1.IsControlledBy(&replicaSets.Items[i], sibling) {
+				ofSibling[replicaSets.Items[i].UID] = true
+			}
+		}
+	}
+
+	var pods corev1.PodList
+	if err := r.List(ctx, &pods, client.InNamespace(cr.Namespace), selector); err != nil {
+		return nil, nil, err
+	}
+	var ours []corev1.Pod
+	var foreign []string
+	for i := range pods.Items {
+		ref := metav1.GetControllerOf(&pods.Items[i])
+		if ref != nil && ofSibling[ref.UID] {
+			continue
+		}
+		if ref == nil || !owned[ref.UID] {
+			foreign = append(foreign, pods.Items[i].Name)
+		} else {
+			ours = append(ours, pods.Items[i])
+		}
+	}
+
+	// Keep the messages stable across list orderings to avoid status churn
+	sort.Strings(foreign)
+	return ours, foreign, nil
+}
+
+// rolloutMessage explains why pods aren't coming up, e.g. "2 pod(s) ImagePullBackOff". Pod problems
+// are more specific than the Deployment conditions, so they take precedence. It is empty while the
+// rollout is healthy.
+func rolloutMessage(dep *appsv1.Deployment, pods []corev1.Pod) string {
+	problems := map[string]int{}
+	for i := range pods {
+		if reason := podProblem(&pods[i]); reason != "" {
+			problems[reason]++
+		}
+	}
+	if len(problems) > 0 {
+		reasons := make([]string, 0, len(problems))
+		for reason := range problems {
+			reasons = append(reasons, reason)
+		}
+		sort.Strings(reasons)
+		parts := make([]string, 0, len(reasons))
+		for _, reason := range reasons {
+			parts = append(parts, fmt.Sprintf("%d pod(s) %s", problems[reason], reason))
+		}
+		return strings.Join(parts, ", ")
+	}
+
+	for _, c := range dep.Status.Conditions {
+		if (c.Type == appsv1.DeploymentReplicaFailure && c.Status == corev1.ConditionTrue) ||
+			(c.Type == appsv1.DeploymentProgressing && c.Status == corev1.ConditionFalse) {
+			return c.Message
+		}
+	}
+	return ""
+}
+
+// podProblem returns the reason a pod is stuck (e.g. ImagePullBackOff, CrashLoopBackOff or
+// Unschedulable), ignoring the waiting states every pod goes through while starting.
+func podProblem(pod *corev1.Pod) string {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodScheduled && c.Status == corev1.ConditionFalse && c.Reason != "" {
+			return c.Reason
+		}
+	}
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, cs := range statuses {
+		if w := cs.State.Waiting; w != nil && w.Reason != "" && w.Reason != "ContainerCreating" && w.Reason != "PodInitializing" {
+			return w.Reason
+		}
+	}
+	return ""
+}
+
+// pruneRenamedChildren deletes Deployments, Services and Ingresses controlled by the
+// SimpleApp whose name no longer matches the computed child name.
+func (r *SimpleAppReconciler) pruneRenamedChildren(ctx context.Context, cr *appsv1alpha1.SimpleApp, name string) error {
+	log := logf.FromContext(ctx)
+	// Every child carries the app's selector labels, which don't depend on the child name, so this
+	// only lists the SimpleApp's own children instead of everything in the namespace
+	scope := []client.ListOption{client.InNamespace(cr.Namespace), client.MatchingLabels(appLabels(cr))}
+
+	var deployments appsv1.DeploymentList
+	if err := r.List(ctx, &deployments, scope...); err != nil {
+		return err
+	}
+	var services corev1.ServiceList
+	if err := r.List(ctx, &services, scope...); err != nil {
+		return err
+	}
+	var ingresses networkingv1.IngressList
+	if err := r.List(ctx, &ingresses, scope...); err != nil {
+		return err
+	}
+	var pdbs policyv1.PodDisruptionBudgetList
+	if err := r.List(ctx, &pdbs, scope...); err != nil {
+		return err
+	}
+	var hpas autoscalingv2.HorizontalPodAutoscalerList
+	if err := r.List(ctx, &hpas, scope...); err != nil {
+		return err
+	}
+	var networkPolicies networkingv1.NetworkPolicyList
+	if err := r.List(ctx, &networkPolicies, scope...); err != nil {
+		return err
+	}
+
+	var stale []client.Object
+	for i := range deployments.Items {
+		if deployments.Items[i].Name != name &&
+			(cr.Spec.Canary == nil || deployments.Items[i].Name != canaryName(name)) {
+			stale = append(stale, &deployments.Items[i])
+		}
+	}
+	for i := range services.Items {
+		if services.Items[i].Name != name && !r.DisableServiceManagement {
+			stale = append(stale, &services.Items[i])
+		}
+	}
+	for i := range ingresses.Items {
+		if ingresses.Items[i].Name != name+"-ingress" {
+			stale = append(stale, &ingresses.Items[i])
+		}
+	}
+	for i := range pdbs.Items {
+		if pdbs.Items[i].Name != name {
+			stale = append(stale, &pdbs.Items[i])
+		}
+	}
+	for i := range hpas.Items {
+		if hpas.Items[i].Name != name {
+			stale = append(stale, &hpas.Items[i])
+		}
+	}
+	for i := range networkPolicies.Items {
+		if networkPolicies.Items[i].Name != name {
+			stale = append(stale, &networkPolicies.Items[i])
+		}
+	}
+
+	// ServiceMonitors are optional; only look for them when their CRD is installed
+	monitors, err := serviceMonitorsAvailable(r.RESTMapper())
+	if err != nil {
+		return err
+	}
+	if monitors {
+		var list unstructured.UnstructuredList
+		list.SetGroupVersionKind(serviceMonitorGVK.GroupVersion().WithKind(serviceMonitorGVK.Kind + "List"))
+		if err := r.List(ctx, &list, scope...); err != nil {
+			return err
+		}
+		for i := range list.Items {
+			if list.Items[i].GetName() != name {
+				stale = append(stale, &list.Items[i])
+			}
+		}
+	}
+
+	for _, obj := range stale {
+		if !metav1.IsControlledBy(obj, cr) {
+			continue
+		}
+		gvk, err := apiutil.GVKForObject(obj, r.Scheme)
+		if err != nil {
+			return err
+		}
+		log.Info("Deleting child left behind by a rename", "Kind", gvk.Kind, "Name", obj.GetName())
+		if err := r.Delete(ctx, obj); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		metrics.RecordChildOperation(gvk.Kind, metrics.OperationDelete)
+	}
+	return nil
+}
+
+// Defaults of the controller-runtime rate limiter, which BaseBackoff and MaxBackoff override.
+const (
+	defaultBaseBackoff = 5 * time.Millisecond
+	defaultMaxBackoff  = 1000 * time.Second
+)
+
+// rateLimiter returns the rate limiter of the controller's work queue: the controller-runtime default,
+// with the per-SimpleApp backoff bounded by BaseBackoff and MaxBackoff.
+func (r *SimpleAppReconciler) rateLimiter() workqueue.TypedRateLimiter[reconcile.Request] {
+	base, maxDelay := r.BaseBackoff, r.MaxBackoff
+	if base == 0 {
+		base = defaultBaseBackoff
+	}
+	if maxDelay == 0 {
+		maxDelay = defaultMaxBackoff
+	}
+	return workqueue.NewTypedMaxOfRateLimiter(
+		workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](base, maxDelay),
+		&workqueue.TypedBucketRateLimiter[reconcile.Request]{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
+	)
+}
+
 // SetupWithManager sets up the controller with the Manager.
+// The manager only starts the workers once the caches of all watched kinds have synced.
 func (r *SimpleAppReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	builder := ctrl.NewControllerManagedBy(mgr).
 		For(&appsv1alpha1.SimpleApp{}).
 		Owns(&appsv1.Deployment{}).
 		Owns(&corev1.Service{}).
 		Owns(&networkingv1.Ingress{}).
-		Complete(r)
+		Owns(&policyv1.PodDisruptionBudget{}).
+		Owns(&networkingv1.NetworkPolicy{}).
+		Owns(&autoscalingv2.HorizontalPodAutoscaler{}).
+		Owns(&batchv1.Job{}).
+		Owns(&corev1.PersistentVolumeClaim{}).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: r.MaxConcurrentReconciles,
+			RateLimiter:             r.rateLimiter(),
+		})
+
+	// Watching a kind whose CRD is missing would fail the manager, so ServiceMonitors are only
+	// watched when the Prometheus Operator was installed before the operator started.
+	available, err := serviceMonitorsAvailable(mgr.GetRESTMapper())
+	if err != nil {
+		return err
+	}
+	if available {
+		sm := &unstructured.Unstructured{}
+		sm.SetGroupVersionKind(serviceMonitorGVK)
+		builder = builder.Owns(sm)
+	}
+
+	return builder.Complete(r)
 }
@@ -18,16 +18,38 @@ package controller
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	k8sappsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	appsv1 "github.com/gxanlvxgx/simple-app-operator/api/v1"
+	"github.com/gxanlvxgx/simple-app-operator/internal/metrics"
 )
 
 var _ = Describe("SimpleApp Controller", func() {
@@ -54,7 +76,7 @@ var _ = Describe("SimpleApp Controller", func() {
 					Spec: appsv1.SimpleAppSpec{
 						Image:         "nginx:latest",
 						ContainerPort: 80,
-						Replicas:      1,
+						Replicas:      ptr.To[int32](1),
 						ServicePort:   80,
 					},
 				}
@@ -68,14 +90,18 @@ var _ = Describe("SimpleApp Controller", func() {
 			err := k8sClient.Get(ctx, typeNamespacedName, resource)
 			Expect(err).NotTo(HaveOccurred())
 
+			// envtest runs no garbage collector, so remove the children explicitly
+			deleteControlledChildren(ctx, resource)
+
 			By("Cleanup the specific resource instance SimpleApp")
 			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
 		})
 		It("should successfully reconcile the resource", func() {
 			By("Reconciling the created resource")
 			controllerReconciler := &SimpleAppReconciler{
-				Client: k8sClient,
-				Scheme: k8sClient.Scheme(),
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
 			}
 
 			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
@@ -85,5 +111,3512 @@ var _ = Describe("SimpleApp Controller", func() {
 			// Add more specific assertions depending on your controller's reconciliation logic.
 			// Example: If you expect a certain status condition after reconciliation, verify it here.
 		})
-	})
-})
+
+		It("should requeue after the resync period when one is configured", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			By("reconciling without a resync period")
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeZero())
+
+			By("reconciling with a resync period")
+			controllerReconciler.ResyncPeriod = 5 * time.Minute
+			result, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(5 * time.Minute))
+		})
+
+		It("should report the Service DNS name in status", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(simpleapp.Status.ServiceDNS).To(Equal("test-resource.default.svc.cluster.local:80"))
+
+			By("changing the Service port")
+			simpleapp.Spec.ServicePort = 8080
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(simpleapp.Status.ServiceDNS).To(Equal("test-resource.default.svc.cluster.local:8080"))
+		})
+
+		It("should expose port 80 when servicePort is omitted", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			By("leaving servicePort out, as without the defaulting webhook")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.ContainerPort = 8080
+			simpleapp.Spec.ServicePort = 0
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			service := &corev1.Service{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			Expect(service.Spec.Ports[0].Port).To(Equal(int32(80)))
+			Expect(service.Spec.Ports[0].TargetPort.IntValue()).To(Equal(8080))
+
+			By("setting an explicit servicePort")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.ServicePort = 8443
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			Expect(service.Spec.Ports[0].Port).To(Equal(int32(8443)))
+			Expect(service.Spec.Ports[0].TargetPort.IntValue()).To(Equal(8080))
+		})
+
+		It("should expose the container port when servicePort is omitted and follows it", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:                          k8sClient,
+				Scheme:                          k8sClient.Scheme(),
+				Recorder:                        record.NewFakeRecorder(100),
+				ServicePortFollowsContainerPort: true,
+			}
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.ContainerPort = 8080
+			simpleapp.Spec.ServicePort = 0
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			service := &corev1.Service{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			Expect(service.Spec.Ports[0].Port).To(Equal(int32(8080)))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(simpleapp.Status.ServiceDNS).To(HaveSuffix(":8080"))
+		})
+
+		It("should target the container port by number or by name", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			service := &corev1.Service{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			Expect(service.Spec.Ports[0].TargetPort).To(Equal(intstr.FromInt(80)))
+
+			By("naming the target port")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.TargetPortName = "web"
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			Expect(service.Spec.Ports[0].TargetPort).To(Equal(intstr.FromString("web")))
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Ports[0].Name).To(Equal("web"))
+
+			By("going back to the port number")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.TargetPortName = ""
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			Expect(service.Spec.Ports[0].TargetPort).To(Equal(intstr.FromInt(80)))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Ports[0].Name).To(BeEmpty())
+		})
+
+		It("should select the pods by spec.selectorLabels", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.SelectorLabels = map[string]string{"app.kubernetes.io/name": "web", "tier": "frontend"}
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Selector.MatchLabels).To(Equal(simpleapp.Spec.SelectorLabels))
+			Expect(deployment.Spec.Template.Labels).To(HaveKeyWithValue("app.kubernetes.io/name", "web"))
+			Expect(deployment.Spec.Template.Labels).To(HaveKeyWithValue("tier", "frontend"))
+			Expect(deployment.Spec.Template.Labels).To(HaveKeyWithValue("app", resourceName))
+			service := &corev1.Service{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			Expect(service.Spec.Selector).To(Equal(simpleapp.Spec.SelectorLabels))
+			Expect(service.Labels).To(HaveKeyWithValue("app", resourceName))
+		})
+
+		It("should only label the Deployment with spec.deploymentLabels", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.DeploymentLabels = map[string]string{"policy.example.com/tier": "critical", "app": "ignored"}
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Labels).To(HaveKeyWithValue("policy.example.com/tier", "critical"))
+			Expect(deployment.Labels).To(HaveKeyWithValue("app", resourceName))
+			Expect(deployment.Spec.Selector.MatchLabels).NotTo(HaveKey("policy.example.com/tier"))
+			Expect(deployment.Spec.Template.Labels).NotTo(HaveKey("policy.example.com/tier"))
+			service := &corev1.Service{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			Expect(service.Labels).NotTo(HaveKey("policy.example.com/tier"))
+
+			By("changing a Deployment label")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.DeploymentLabels["policy.example.com/tier"] = "standard"
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Labels).To(HaveKeyWithValue("policy.example.com/tier", "standard"))
+		})
+
+		It("should report the live cluster IP of the Service in status", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			service := &corev1.Service{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			if service.Spec.ClusterIP == "" {
+				By("allocating a cluster IP, as the API server would")
+				service.Spec.ClusterIP = "10.96.0.42"
+				Expect(k8sClient.Update(ctx, service)).To(Succeed())
+				_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(simpleapp.Status.ServiceEndpoint).NotTo(BeNil())
+			Expect(simpleapp.Status.ServiceEndpoint.ClusterIP).To(Equal(service.Spec.ClusterIP))
+			Expect(simpleapp.Status.ServiceEndpoint.Port).To(Equal(service.Spec.Ports[0].Port))
+		})
+
+		It("should track the observed generation of spec edits in status", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(simpleapp.Status.ObservedGeneration).To(Equal(simpleapp.Generation))
+			Expect(simpleapp.Status.LastReconcileTime).NotTo(BeNil())
+			reconciled := simpleapp.Status.LastReconcileTime
+
+			By("editing the spec")
+			simpleapp.Spec.Image = "nginx:1.27"
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			generation := simpleapp.Generation
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(simpleapp.Status.ObservedGeneration).To(Equal(generation))
+			Expect(simpleapp.Status.LastReconcileTime.Time).NotTo(BeTemporally("<", reconciled.Time))
+		})
+
+		It("should summarize a ready app in status", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			deployment.Status.Replicas = 1
+			deployment.Status.ReadyReplicas = 1
+			Expect(k8sClient.Status().Update(ctx, deployment)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			service := &corev1.Service{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			clusterIP := service.Spec.ClusterIP
+			if clusterIP == "" {
+				// Only the API server allocates cluster IPs
+				clusterIP = "<pending>"
+			}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(simpleapp.Status.Summary).To(Equal("1/1 ready, ClusterIP " + clusterIP + ":80, image nginx:latest"))
+
+			By("reconciling again without changes")
+			resourceVersion := simpleapp.ResourceVersion
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(simpleapp.ResourceVersion).To(Equal(resourceVersion), "an unchanged summary must not rewrite status")
+		})
+
+		It("should not write status for watch events that change nothing it reports", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			for range 2 {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			resourceVersion := simpleapp.ResourceVersion
+
+			By("updating Deployment status fields the SimpleApp status doesn't reflect")
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			deployment.Status.ObservedGeneration = deployment.Generation
+			deployment.Status.Replicas = 1
+			Expect(k8sClient.Status().Update(ctx, deployment)).To(Succeed())
+
+			for range 3 {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(simpleapp.ResourceVersion).To(Equal(resourceVersion), "unchanged status must not be written")
+		})
+
+		It("should remove and recreate the Service when exposeService is toggled", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, &corev1.Service{})).To(Succeed())
+
+			By("turning the Service off")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.ExposeService = ptr.To(false)
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, typeNamespacedName, &corev1.Service{}))).To(BeTrue())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(simpleapp.Status.ServiceDNS).To(BeEmpty())
+			Expect(simpleapp.Status.Summary).To(Equal("0/1 ready, no Service, image nginx:latest"))
+
+			By("turning the Service back on")
+			simpleapp.Spec.ExposeService = ptr.To(true)
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, &corev1.Service{})).To(Succeed())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(simpleapp.Status.ServiceDNS).To(Equal("test-resource.default.svc.cluster.local:80"))
+		})
+
+		It("should use an external Service instead of the generated one", func() {
+			recorder := record.NewFakeRecorder(100)
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, &corev1.Service{})).To(Succeed())
+
+			By("handing the Service over to the mesh")
+			external := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "mesh-web", Namespace: "default"},
+				Spec: corev1.ServiceSpec{
+					Selector: map[string]string{"app": resourceName},
+					Ports:    []corev1.ServicePort{{Port: 80}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, external)).To(Succeed())
+			defer func() { Expect(k8sClient.Delete(ctx, external)).To(Succeed()) }()
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.ExternalService = "mesh-web"
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, typeNamespacedName, &corev1.Service{}))).To(BeTrue())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(simpleapp.Status.ServiceDNS).To(Equal("mesh-web.default.svc.cluster.local"))
+			Expect(recorder.Events).NotTo(Receive())
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(external), external)).To(Succeed())
+			Expect(external.OwnerReferences).To(BeEmpty())
+
+			By("referencing an ExternalName Service")
+			externalName := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "mesh-egress", Namespace: "default"},
+				Spec: corev1.ServiceSpec{
+					Type:         corev1.ServiceTypeExternalName,
+					ExternalName: "web.example.com",
+					Selector:     map[string]string{"app": resourceName},
+				},
+			}
+			Expect(k8sClient.Create(ctx, externalName)).To(Succeed())
+			defer func() { Expect(k8sClient.Delete(ctx, externalName)).To(Succeed()) }()
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.ExternalService = "mesh-egress"
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(simpleapp.Status.ServiceDNS).To(Equal("web.example.com"))
+			Expect(recorder.Events).NotTo(Receive())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.ExternalService = "mesh-web"
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("pointing the external Service at other pods")
+			external.Spec.Selector = map[string]string{"app": "other"}
+			Expect(k8sClient.Update(ctx, external)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).To(Receive(ContainSubstring("ExternalServiceMismatch")))
+
+			By("referencing a Service that doesn't exist")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.ExternalService = "missing"
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).To(Receive(ContainSubstring("ExternalServiceNotFound")))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(simpleapp.Status.ServiceDNS).To(BeEmpty())
+		})
+
+		It("should switch the Service to and from headless", func() {
+			recorder := record.NewFakeRecorder(100)
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("making the Service headless")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.Headless = true
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			service := &corev1.Service{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			Expect(service.Spec.ClusterIP).To(Equal(corev1.ClusterIPNone))
+			Expect(service.Spec.Ports).To(HaveLen(1))
+			Expect(service.Spec.Ports[0].Name).To(Equal("http"))
+			Expect(recorder.Events).To(Receive(ContainSubstring("ServiceRecreated")))
+
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Subdomain).To(Equal(resourceName))
+
+			By("reconciling again without changes")
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).NotTo(Receive())
+
+			By("going back to a regular Service")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.Headless = false
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			Expect(service.Spec.ClusterIP).NotTo(Equal(corev1.ClusterIPNone))
+			Expect(service.Spec.Ports[0].Name).To(BeEmpty())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Subdomain).To(BeEmpty())
+		})
+
+		It("should publish not-ready addresses of a headless Service", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.Headless = true
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			service := &corev1.Service{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			Expect(service.Spec.PublishNotReadyAddresses).To(BeFalse())
+
+			By("enabling publishNotReadyAddresses")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.PublishNotReadyAddresses = ptr.To(true)
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			Expect(service.Spec.PublishNotReadyAddresses).To(BeTrue())
+
+			By("disabling it again")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.PublishNotReadyAddresses = nil
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			Expect(service.Spec.PublishNotReadyAddresses).To(BeFalse())
+		})
+
+		It("should change the Service type in place", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			service := &corev1.Service{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			uid := service.UID
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.ServiceType = corev1.ServiceTypeNodePort
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			Expect(service.Spec.Type).To(Equal(corev1.ServiceTypeNodePort))
+			Expect(service.Spec.ExternalTrafficPolicy).To(Equal(corev1.ServiceExternalTrafficPolicyCluster))
+			Expect(service.UID).To(Equal(uid))
+
+			By("preserving client source IPs")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyLocal
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			Expect(service.Spec.ExternalTrafficPolicy).To(Equal(corev1.ServiceExternalTrafficPolicyLocal))
+
+			By("going back to a ClusterIP Service")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.ServiceType = corev1.ServiceTypeClusterIP
+			simpleapp.Spec.ExternalTrafficPolicy = ""
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			Expect(service.Spec.Type).To(Equal(corev1.ServiceTypeClusterIP))
+			Expect(service.Spec.ExternalTrafficPolicy).To(BeEmpty())
+		})
+
+		It("should rename a Service port in place and keep its node port", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.ServiceType = corev1.ServiceTypeNodePort
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			service := &corev1.Service{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			if service.Spec.Ports[0].NodePort == 0 {
+				By("allocating a node port, as the API server would")
+				service.Spec.Ports[0].NodePort = 30080
+				Expect(k8sClient.Update(ctx, service)).To(Succeed())
+			}
+			uid, nodePort := service.UID, service.Spec.Ports[0].NodePort
+			Expect(service.Spec.Ports[0].Name).To(BeEmpty())
+
+			By("serving metrics on the app port, which names it")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.Metrics = &appsv1.MetricsSpec{Port: simpleapp.Spec.ContainerPort, PortName: "web"}
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			Expect(service.Spec.Ports).To(HaveLen(1))
+			Expect(service.Spec.Ports[0].Name).To(Equal("web"))
+			Expect(service.Spec.Ports[0].NodePort).To(Equal(nodePort))
+			Expect(service.UID).To(Equal(uid))
+
+			By("renaming the port")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.Metrics.PortName = "http-metrics"
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			Expect(service.Spec.Ports[0].Name).To(Equal("http-metrics"))
+			Expect(service.Spec.Ports[0].NodePort).To(Equal(nodePort))
+
+			By("converging without further updates")
+			resourceVersion := service.ResourceVersion
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			Expect(service.ResourceVersion).To(Equal(resourceVersion))
+		})
+
+		It("should leave Services alone when Service management is disabled", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:                   k8sClient,
+				Scheme:                   k8sClient.Scheme(),
+				Recorder:                 record.NewFakeRecorder(100),
+				DisableServiceManagement: true,
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, typeNamespacedName, &corev1.Service{}))).To(BeTrue())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, &k8sappsv1.Deployment{})).To(Succeed())
+
+			By("reporting a Service created by another controller without changing it")
+			service := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: corev1.ServiceSpec{
+					Selector: map[string]string{"app": resourceName, "owner": "mesh"},
+					Ports:    []corev1.ServicePort{{Port: 8081, TargetPort: intstr.FromInt(80)}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, service)).To(Succeed())
+			DeferCleanup(func() { Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, service))).To(Succeed()) })
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			Expect(service.OwnerReferences).To(BeEmpty())
+			Expect(service.Spec.Ports[0].Port).To(Equal(int32(8081)))
+			Expect(service.Spec.Selector).To(HaveKeyWithValue("owner", "mesh"))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(simpleapp.Status.ServiceEndpoint).NotTo(BeNil())
+			Expect(simpleapp.Status.ServiceEndpoint.Port).To(Equal(int32(8081)))
+
+			By("not deleting it when the SimpleApp stops exposing a Service")
+			simpleapp.Spec.ExposeService = ptr.To(false)
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+		})
+
+		It("should apply and update the session affinity of the Service", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			service := &corev1.Service{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			Expect(service.Spec.SessionAffinity).To(Equal(corev1.ServiceAffinityNone))
+			Expect(service.Spec.SessionAffinityConfig).To(BeNil())
+
+			By("enabling sticky sessions")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.SessionAffinity = corev1.ServiceAffinityClientIP
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			Expect(service.Spec.SessionAffinity).To(Equal(corev1.ServiceAffinityClientIP))
+			Expect(*service.Spec.SessionAffinityConfig.ClientIP.TimeoutSeconds).To(Equal(corev1.DefaultClientIPServiceAffinitySeconds))
+
+			By("shortening the timeout")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.SessionAffinityTimeoutSeconds = ptr.To[int32](600)
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			Expect(*service.Spec.SessionAffinityConfig.ClientIP.TimeoutSeconds).To(Equal(int32(600)))
+
+			By("turning sticky sessions off again")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.SessionAffinity = corev1.ServiceAffinityNone
+			simpleapp.Spec.SessionAffinityTimeoutSeconds = nil
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			Expect(service.Spec.SessionAffinity).To(Equal(corev1.ServiceAffinityNone))
+			Expect(service.Spec.SessionAffinityConfig).To(BeNil())
+		})
+
+		It("should keep the common labels on the Deployment and Service", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:       k8sClient,
+				Scheme:       k8sClient.Scheme(),
+				Recorder:     record.NewFakeRecorder(100),
+				CommonLabels: map[string]string{"team": "platform", "cost-center": "42"},
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Labels).To(HaveKeyWithValue("team", "platform"))
+			Expect(deployment.Labels).To(HaveKeyWithValue("cost-center", "42"))
+			Expect(deployment.Labels).To(HaveKeyWithValue("app", resourceName))
+			service := &corev1.Service{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			Expect(service.Labels).To(HaveKeyWithValue("team", "platform"))
+			Expect(service.Labels).To(HaveKeyWithValue("app", resourceName))
+
+			By("removing the label from the Deployment by hand")
+			delete(deployment.Labels, "team")
+			Expect(k8sClient.Update(ctx, deployment)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Labels).To(HaveKeyWithValue("team", "platform"))
+
+			By("removing the label from the Service by hand")
+			delete(service.Labels, "team")
+			Expect(k8sClient.Update(ctx, service)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			Expect(service.Labels).To(HaveKeyWithValue("team", "platform"))
+
+			By("changing the labels, which the Service hash covers")
+			controllerReconciler.CommonLabels["cost-center"] = "43"
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			Expect(service.Labels).To(HaveKeyWithValue("cost-center", "43"))
+		})
+
+		It("should honour the per-object log level annotation", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			By("reconciling with the default verbosity")
+			logger, lines := capturingLogger(0)
+			_, err := controllerReconciler.Reconcile(logf.IntoContext(ctx, logger), reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*lines).To(ContainElement(ContainSubstring("Successfully reconciled SimpleApp")))
+			Expect(*lines).NotTo(ContainElement(ContainSubstring("Reconciling SimpleApp")))
+
+			By("enabling debug logs for this SimpleApp only")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Annotations = map[string]string{appsv1.LogLevelAnnotation: "debug"}
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			logger, lines = capturingLogger(0)
+			_, err = controllerReconciler.Reconcile(logf.IntoContext(ctx, logger), reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*lines).To(ContainElement(ContainSubstring("Reconciling SimpleApp")))
+
+			By("silencing info logs")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Annotations[appsv1.LogLevelAnnotation] = "error"
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			logger, lines = capturingLogger(0)
+			_, err = controllerReconciler.Reconcile(logf.IntoContext(ctx, logger), reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*lines).To(BeEmpty())
+
+			By("falling back to the default verbosity on an invalid level")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Annotations[appsv1.LogLevelAnnotation] = "loud"
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			logger, lines = capturingLogger(0)
+			_, err = controllerReconciler.Reconcile(logf.IntoContext(ctx, logger), reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*lines).To(ContainElement(ContainSubstring("Ignoring invalid log level annotation")))
+			Expect(*lines).To(ContainElement(ContainSubstring("Successfully reconciled SimpleApp")))
+		})
+
+		It("should run the pods under the configured ServiceAccount", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			By("setting a ServiceAccountName on the SimpleApp")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.ServiceAccountName = "app-runner"
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.ServiceAccountName).To(Equal("app-runner"))
+
+			By("clearing the ServiceAccountName to fall back to the namespace default")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.ServiceAccountName = ""
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.ServiceAccountName).To(BeEmpty())
+		})
+
+		It("should control whether the ServiceAccount token is mounted", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.AutomountServiceAccountToken).To(BeNil())
+
+			By("opting out of the token")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.AutomountServiceAccountToken = ptr.To(false)
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.AutomountServiceAccountToken).To(HaveValue(BeFalse()))
+
+			By("falling back to the ServiceAccount's setting")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.AutomountServiceAccountToken = nil
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.AutomountServiceAccountToken).To(BeNil())
+		})
+
+		It("should limit the application container to spec.gpus GPUs", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.GPUs = 2
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			resources := deployment.Spec.Template.Spec.Containers[0].Resources
+			Expect(resources.Limits).To(HaveKey(corev1.ResourceName("nvidia.com/gpu")))
+			Expect(resources.Limits.Name("nvidia.com/gpu", resource.DecimalSI).Value()).To(Equal(int64(2)))
+
+			By("defaulting the request and setting a memory limit out of band")
+			resources.Requests = corev1.ResourceList{"nvidia.com/gpu": resource.MustParse("2")}
+			resources.Limits[corev1.ResourceMemory] = resource.MustParse("1Gi")
+			deployment.Spec.Template.Spec.Containers[0].Resources = resources
+			Expect(k8sClient.Update(ctx, deployment)).To(Succeed())
+
+			By("dropping the GPUs")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.GPUs = 0
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			resources = deployment.Spec.Template.Spec.Containers[0].Resources
+			Expect(resources.Limits).NotTo(HaveKey(corev1.ResourceName("nvidia.com/gpu")))
+			Expect(resources.Requests).NotTo(HaveKey(corev1.ResourceName("nvidia.com/gpu")))
+			Expect(resources.Limits).To(HaveKey(corev1.ResourceMemory))
+		})
+
+		It("should run the pods with spec.priorityClassName", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.PriorityClassName).To(BeEmpty())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.PriorityClassName = "latency-critical"
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.PriorityClassName).To(Equal("latency-critical"))
+		})
+
+		It("should apply host aliases and the DNS configuration to the pods", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.DNSPolicy).To(Equal(corev1.DNSClusterFirst))
+			Expect(deployment.Spec.Template.Spec.HostAliases).To(BeEmpty())
+			Expect(deployment.Spec.Template.Spec.DNSConfig).To(BeNil())
+
+			By("adding a hosts entry and resolver options")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.HostAliases = []corev1.HostAlias{{IP: "10.0.0.5", Hostnames: []string{"legacy-db"}}}
+			simpleapp.Spec.DNSPolicy = corev1.DNSNone
+			simpleapp.Spec.DNSConfig = &corev1.PodDNSConfig{
+				Nameservers: []string{"10.0.0.53"},
+				Searches:    []string{"corp.example.com"},
+				Options:     []corev1.PodDNSConfigOption{{Name: "ndots", Value: ptr.To("2")}},
+			}
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.HostAliases).To(Equal(simpleapp.Spec.HostAliases))
+			Expect(deployment.Spec.Template.Spec.DNSPolicy).To(Equal(corev1.DNSNone))
+			Expect(deployment.Spec.Template.Spec.DNSConfig).To(Equal(simpleapp.Spec.DNSConfig))
+
+			By("going back to the cluster DNS")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.HostAliases = nil
+			simpleapp.Spec.DNSPolicy = ""
+			simpleapp.Spec.DNSConfig = nil
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.HostAliases).To(BeEmpty())
+			Expect(deployment.Spec.Template.Spec.DNSPolicy).To(Equal(corev1.DNSClusterFirst))
+			Expect(deployment.Spec.Template.Spec.DNSConfig).To(BeNil())
+		})
+
+		It("should record the change cause of each rollout for kubectl rollout history", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Annotations).To(HaveKeyWithValue("kubernetes.io/change-cause",
+				fmt.Sprintf("SimpleApp %s generation %d: image %s", resourceName, simpleapp.Generation, simpleapp.Spec.Image)))
+
+			By("changing the image")
+			simpleapp.Spec.Image = "nginx:1.27"
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			changeCause := fmt.Sprintf("SimpleApp %s generation %d: image nginx:1.27", resourceName, simpleapp.Generation)
+			Expect(deployment.Annotations).To(HaveKeyWithValue("kubernetes.io/change-cause", changeCause))
+
+			By("scaling, which doesn't start a revision")
+			simpleapp.Spec.Replicas = ptr.To(int32(3))
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Replicas).To(HaveValue(Equal(int32(3))))
+			Expect(deployment.Annotations).To(HaveKeyWithValue("kubernetes.io/change-cause", changeCause))
+		})
+
+		It("should pause and resume the rollout of the Deployment", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("pausing and changing the image")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.Paused = true
+			simpleapp.Spec.Image = "nginx:1.27"
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Paused).To(BeTrue())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("nginx:1.27"))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			paused := meta.FindStatusCondition(simpleapp.Status.Conditions, appsv1.ConditionPaused)
+			Expect(paused).NotTo(BeNil())
+			Expect(paused.Status).To(Equal(metav1.ConditionTrue))
+
+			By("resuming the rollout")
+			simpleapp.Spec.Paused = false
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Paused).To(BeFalse())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(meta.FindStatusCondition(simpleapp.Status.Conditions, appsv1.ConditionPaused)).To(BeNil())
+		})
+
+		It("should record an image rollout only when the image changes", func() {
+			recorder := record.NewFakeRecorder(100)
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("changing something else than the image")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.Replicas = ptr.To[int32](2)
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).NotTo(Receive(ContainSubstring("ImageUpdated")))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(simpleapp.Status.Message).To(BeEmpty())
+
+			By("changing the image")
+			simpleapp.Spec.Image = "nginx:1.27"
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).To(Receive(Equal("Normal ImageUpdated Rolling out image nginx:latest -> nginx:1.27")))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(simpleapp.Status.Message).To(Equal("Rolling out image nginx:1.27"))
+
+			By("keeping the message until every replica runs the new image")
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).NotTo(Receive(ContainSubstring("ImageUpdated")))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(simpleapp.Status.Message).To(Equal("Rolling out image nginx:1.27"))
+
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			deployment.Status.ObservedGeneration = deployment.Generation
+			deployment.Status.Replicas = 2
+			deployment.Status.UpdatedReplicas = 2
+			Expect(k8sClient.Status().Update(ctx, deployment)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(simpleapp.Status.Message).To(BeEmpty())
+		})
+
+		It("should scale to zero and keep the Service", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.Replicas = ptr.To[int32](0)
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Replicas).To(HaveValue(BeZero()))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, &corev1.Service{})).To(Succeed())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(simpleapp.Status.Message).To(Equal("Scaled to zero"))
+			Expect(simpleapp.Status.Summary).To(HavePrefix("scaled to zero, ClusterIP "))
+			ready := meta.FindStatusCondition(simpleapp.Status.Conditions, appsv1.ConditionReady)
+			Expect(ready).NotTo(BeNil())
+			Expect(ready.Status).To(Equal(metav1.ConditionTrue))
+			Expect(ready.Reason).To(Equal("ScaledToZero"))
+
+			By("scaling back up")
+			simpleapp.Spec.Replicas = ptr.To[int32](2)
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Replicas).To(HaveValue(Equal(int32(2))))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(simpleapp.Status.Message).To(BeEmpty())
+			Expect(meta.FindStatusCondition(simpleapp.Status.Conditions, appsv1.ConditionReady).Reason).To(Equal("ReplicasNotReady"))
+		})
+
+		It("should treat an omitted replica count as 1", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			By("leaving replicas out, as without the CRD default")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.Replicas = nil
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Replicas).To(HaveValue(Equal(int32(1))))
+		})
+
+		It("should let the replicas-override annotation take precedence over spec.replicas", func() {
+			recorder := record.NewFakeRecorder(100)
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
+			}
+
+			By("overriding the replica count")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			metav1.SetMetaDataAnnotation(&simpleapp.ObjectMeta, appsv1.ReplicasOverrideAnnotation, "5")
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(*deployment.Spec.Replicas).To(Equal(int32(5)))
+
+			By("setting an invalid override")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			metav1.SetMetaDataAnnotation(&simpleapp.ObjectMeta, appsv1.ReplicasOverrideAnnotation, "many")
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Replicas).To(Equal(simpleapp.Spec.Replicas))
+			Expect(recorder.Events).To(Receive(ContainSubstring("InvalidReplicasOverride")))
+
+			By("removing the override")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			delete(simpleapp.Annotations, appsv1.ReplicasOverrideAnnotation)
+			simpleapp.Spec.Replicas = ptr.To[int32](2)
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(*deployment.Spec.Replicas).To(Equal(int32(2)))
+			Expect(recorder.Events).NotTo(Receive())
+
+			By("overriding it under the deprecated key")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			metav1.SetMetaDataAnnotation(&simpleapp.ObjectMeta, appsv1.DeprecatedReplicasOverrideAnnotation, "4")
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(*deployment.Spec.Replicas).To(Equal(int32(4)))
+		})
+
+		It("should mount ConfigMap volumes and warn about missing ConfigMaps", func() {
+			recorder := record.NewFakeRecorder(100)
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
+			}
+
+			By("referencing a ConfigMap that does not exist yet")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.Volumes = []appsv1.VolumeSpec{{Name: "config", ConfigMap: "app-config", MountPath: "/etc/app"}}
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			podSpec := deployment.Spec.Template.Spec
+			Expect(podSpec.Volumes).To(HaveLen(1))
+			Expect(podSpec.Volumes[0].ConfigMap).NotTo(BeNil())
+			Expect(podSpec.Volumes[0].ConfigMap.Name).To(Equal("app-config"))
+			Expect(podSpec.Containers[0].VolumeMounts).To(ConsistOf(corev1.VolumeMount{
+				Name: "config", MountPath: "/etc/app", ReadOnly: true,
+			}))
+			Expect(recorder.Events).To(Receive(ContainSubstring("ConfigMapNotFound")))
+
+			By("removing the volume from the spec")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.Volumes = nil
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Volumes).To(BeEmpty())
+			Expect(deployment.Spec.Template.Spec.Containers[0].VolumeMounts).To(BeEmpty())
+		})
+
+		It("should mount emptyDir and PersistentVolumeClaim volumes and warn about missing claims", func() {
+			recorder := record.NewFakeRecorder(100)
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
+			}
+
+			By("referencing a claim that does not exist yet")
+			sizeLimit := resource.MustParse("1Gi")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.Volumes = []appsv1.VolumeSpec{
+				{Name: "scratch", EmptyDir: &corev1.EmptyDirVolumeSource{SizeLimit: &sizeLimit}, MountPath: "/tmp"},
+				{Name: "data", PersistentVolumeClaim: "app-data", MountPath: "/var/lib/app"},
+			}
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			podSpec := deployment.Spec.Template.Spec
+			Expect(podSpec.Volumes).To(HaveLen(2))
+			Expect(podSpec.Volumes[0].EmptyDir).NotTo(BeNil())
+			Expect(podSpec.Volumes[0].EmptyDir.SizeLimit.Equal(sizeLimit)).To(BeTrue())
+			Expect(podSpec.Volumes[1].PersistentVolumeClaim).To(Equal(&corev1.PersistentVolumeClaimVolumeSource{ClaimName: "app-data"}))
+			Expect(podSpec.Containers[0].VolumeMounts).To(ConsistOf(
+				corev1.VolumeMount{Name: "scratch", MountPath: "/tmp"},
+				corev1.VolumeMount{Name: "data", MountPath: "/var/lib/app"},
+			))
+			Expect(recorder.Events).To(Receive(ContainSubstring("PersistentVolumeClaimNotFound")))
+
+			By("creating the claim")
+			pvc := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "app-data", Namespace: "default"},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					Resources: corev1.VolumeResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, pvc)).To(Succeed())
+			DeferCleanup(func() { Expect(k8sClient.Delete(ctx, pvc)).To(Succeed()) })
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).NotTo(Receive(ContainSubstring("PersistentVolumeClaimNotFound")))
+		})
+
+		It("should inject environment variables from Secrets", func() {
+			recorder := record.NewFakeRecorder(100)
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
+			}
+
+			By("creating one of the two referenced Secrets")
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "db-credentials", Namespace: "default"},
+				StringData: map[string]string{"DB_PASSWORD": "s3cret"},
+			}
+			Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+			DeferCleanup(func() { Expect(k8sClient.Delete(ctx, secret)).To(Succeed()) })
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.EnvFromSecret = []string{"db-credentials", "api-token"}
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			envFrom := deployment.Spec.Template.Spec.Containers[0].EnvFrom
+			Expect(envFrom).To(HaveLen(2))
+			Expect(envFrom[0].SecretRef.Name).To(Equal("db-credentials"))
+			Expect(envFrom[1].SecretRef.Name).To(Equal("api-token"))
+
+			By("warning only about the missing Secret")
+			Expect(recorder.Events).To(Receive(And(ContainSubstring("SecretNotFound"), ContainSubstring("api-token"))))
+			Expect(recorder.Events).NotTo(Receive())
+
+			By("dropping a Secret from the spec")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.EnvFromSecret = []string{"db-credentials"}
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].EnvFrom).To(HaveLen(1))
+		})
+
+		It("should pass spec.logLevel to the container as LOG_LEVEL", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			By("leaving the environment alone without a log level")
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Env).To(BeEmpty())
+
+			By("setting a log level")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.LogLevel = "debug"
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Env).To(ConsistOf(corev1.EnvVar{Name: "LOG_LEVEL", Value: "debug"}))
+
+			By("changing it")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.LogLevel = "warn"
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Env).To(ConsistOf(corev1.EnvVar{Name: "LOG_LEVEL", Value: "warn"}))
+
+			By("clearing it")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.LogLevel = ""
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Env).To(BeEmpty())
+		})
+
+		It("should request a dual-stack Service when enabled", func() {
+			recorder := record.NewFakeRecorder(100)
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			service := &corev1.Service{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			Expect(service.Spec.IPFamilyPolicy).To(HaveValue(Equal(corev1.IPFamilyPolicySingleStack)))
+			if len(service.Spec.IPFamilies) == 0 {
+				// Stand in for the API server, which assigns the cluster's IP family
+				service.Spec.IPFamilies = []corev1.IPFamily{corev1.IPv4Protocol}
+				Expect(k8sClient.Update(ctx, service)).To(Succeed())
+			}
+
+			By("enabling dual-stack on a single-stack cluster")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.DualStack = true
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			Expect(service.Spec.IPFamilyPolicy).To(HaveValue(Equal(corev1.IPFamilyPolicyPreferDualStack)))
+			Expect(recorder.Events).To(Receive(ContainSubstring("DualStackUnavailable")))
+		})
+
+		It("should expose the metrics port without the Prometheus Operator installed", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.Metrics = &appsv1.MetricsSpec{Port: 9090, PortName: "metrics", Path: "/metrics", Interval: "30s"}
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Ports).To(ContainElement(And(
+				HaveField("Name", "metrics"), HaveField("ContainerPort", int32(9090)))))
+
+			service := &corev1.Service{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			Expect(service.Labels).To(HaveKeyWithValue("app", resourceName))
+			Expect(service.Spec.Ports).To(HaveLen(2))
+			Expect(service.Spec.Ports[0].Name).To(Equal("http"))
+			Expect(service.Spec.Ports[1].Name).To(Equal("metrics"))
+			Expect(service.Spec.Ports[1].Port).To(Equal(int32(9090)))
+		})
+
+		It("should expose the admin port next to the main one", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.AdminPort = ptr.To(int32(8081))
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Ports).To(ContainElement(And(
+				HaveField("Name", "admin"), HaveField("ContainerPort", int32(8081)))))
+
+			service := &corev1.Service{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			Expect(service.Spec.Ports).To(HaveLen(2))
+			Expect(service.Spec.Ports[0].Name).To(Equal("http"))
+			Expect(service.Spec.Ports[1].Name).To(Equal("admin"))
+			Expect(service.Spec.Ports[1].Port).To(Equal(int32(8081)))
+			Expect(service.Spec.Ports[1].TargetPort).To(Equal(intstr.FromInt(8081)))
+		})
+
+		It("should apply graceful termination settings", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.TerminationGracePeriodSeconds).To(HaveValue(Equal(int64(30))))
+			Expect(deployment.Spec.Template.Spec.Containers[0].Lifecycle).To(BeNil())
+
+			By("setting a grace period and a preStop hook")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.TerminationGracePeriodSeconds = ptr.To(int64(120))
+			simpleapp.Spec.PreStop = &appsv1.LifecycleHook{
+				Exec: &corev1.ExecAction{Command: []string{"/bin/sh", "-c", "sleep 10"}},
+			}
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.TerminationGracePeriodSeconds).To(HaveValue(Equal(int64(120))))
+			Expect(deployment.Spec.Template.Spec.Containers[0].Lifecycle.PreStop.Exec.Command).To(ContainElement("sleep 10"))
+
+			By("switching the hook to an HTTP request")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.PreStop = &appsv1.LifecycleHook{
+				HTTPGet: &corev1.HTTPGetAction{Path: "/drain", Port: intstr.FromInt32(80)},
+			}
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			preStop := deployment.Spec.Template.Spec.Containers[0].Lifecycle.PreStop
+			Expect(preStop.Exec).To(BeNil())
+			Expect(preStop.HTTPGet.Path).To(Equal("/drain"))
+		})
+
+		It("should wire the postStart and preStop hooks of spec.lifecycle", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.Lifecycle = &appsv1.LifecycleSpec{
+				PostStart: &appsv1.LifecycleHook{
+					HTTPGet: &corev1.HTTPGetAction{Path: "/warmup", Port: intstr.FromInt32(80)},
+				},
+				PreStop: &appsv1.LifecycleHook{Exec: &corev1.ExecAction{Command: []string{"drain"}}},
+			}
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			lifecycle := deployment.Spec.Template.Spec.Containers[0].Lifecycle
+			Expect(lifecycle.PostStart.HTTPGet.Path).To(Equal("/warmup"))
+			Expect(lifecycle.PostStart.HTTPGet.Scheme).To(Equal(corev1.URISchemeHTTP))
+			Expect(lifecycle.PreStop.Exec.Command).To(Equal([]string{"drain"}))
+
+			By("removing the postStart hook")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.Lifecycle.PostStart = nil
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			lifecycle = deployment.Spec.Template.Spec.Containers[0].Lifecycle
+			Expect(lifecycle.PostStart).To(BeNil())
+			Expect(lifecycle.PreStop.Exec.Command).To(Equal([]string{"drain"}))
+		})
+
+		It("should apply and update the startup probe", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].StartupProbe).To(BeNil())
+
+			By("allowing up to 120s to boot")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.StartupProbe = &corev1.Probe{
+				ProbeHandler: corev1.ProbeHandler{
+					HTTPGet: &corev1.HTTPGetAction{Path: "/healthz", Port: intstr.FromInt32(80)},
+				},
+				FailureThreshold: 12,
+			}
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			probe := deployment.Spec.Template.Spec.Containers[0].StartupProbe
+			Expect(probe).NotTo(BeNil())
+			Expect(probe.HTTPGet.Path).To(Equal("/healthz"))
+			Expect(probe.FailureThreshold).To(Equal(int32(12)))
+			Expect(probe.PeriodSeconds).To(Equal(int32(10)))
+
+			By("reconciling again without changes")
+			generation := deployment.Generation
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Generation).To(Equal(generation))
+
+			By("removing the probe")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.StartupProbe = nil
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].StartupProbe).To(BeNil())
+		})
+
+		It("should apply and update the working directory and stdin/tty", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.WorkingDir = "/srv/app"
+			simpleapp.Spec.Stdin = true
+			simpleapp.Spec.TTY = true
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			container := deployment.Spec.Template.Spec.Containers[0]
+			Expect(container.WorkingDir).To(Equal("/srv/app"))
+			Expect(container.Stdin).To(BeTrue())
+			Expect(container.TTY).To(BeTrue())
+
+			By("changing the working directory and dropping the terminal")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.WorkingDir = "/app"
+			simpleapp.Spec.Stdin = false
+			simpleapp.Spec.TTY = false
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			container = deployment.Spec.Template.Spec.Containers[0]
+			Expect(container.WorkingDir).To(Equal("/app"))
+			Expect(container.Stdin).To(BeFalse())
+			Expect(container.TTY).To(BeFalse())
+		})
+
+		It("should apply the image pull policy", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].ImagePullPolicy).To(Equal(corev1.PullIfNotPresent))
+
+			By("forcing pulls of the mutable tag")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.ImagePullPolicy = corev1.PullAlways
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].ImagePullPolicy).To(Equal(corev1.PullAlways))
+		})
+
+		It("should pass digest references through and report the running image", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(simpleapp.Status.RunningImage).To(Equal(simpleapp.Spec.Image))
+
+			By("pinning the image by digest")
+			const pinned = "docker.io/library/nginx@sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31"
+			simpleapp.Spec.Image = pinned
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal(pinned))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(simpleapp.Status.RunningImage).To(Equal(pinned))
+		})
+
+		It("should restart the pods when the rollout trigger changes", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Annotations).NotTo(HaveKey(appsv1.RolloutTriggerAnnotation))
+
+			By("restarting the app with kubectl rollout restart")
+			metav1.SetMetaDataAnnotation(&deployment.Spec.Template.ObjectMeta, "kubectl.kubernetes.io/restartedAt", "2025-01-01T00:00:00Z")
+			Expect(k8sClient.Update(ctx, deployment)).To(Succeed())
+
+			By("setting a rollout trigger")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.RolloutTrigger = "2025-01-02T00:00:00Z"
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Annotations).To(HaveKeyWithValue(appsv1.RolloutTriggerAnnotation, "2025-01-02T00:00:00Z"))
+			Expect(deployment.Spec.Template.Annotations).To(HaveKey("kubectl.kubernetes.io/restartedAt"))
+
+			By("removing the rollout trigger")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.RolloutTrigger = ""
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Annotations).NotTo(HaveKey(appsv1.RolloutTriggerAnnotation))
+		})
+
+		It("should run init containers before the application", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.InitContainers).To(BeEmpty())
+
+			By("adding a migration step")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.InitContainers = []corev1.Container{{
+				Name:    "migrate",
+				Image:   "migrate/migrate:v4.17.0",
+				Command: []string{"migrate", "up"},
+			}}
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.InitContainers).To(HaveLen(1))
+			Expect(deployment.Spec.Template.Spec.InitContainers[0].Command).To(Equal([]string{"migrate", "up"}))
+			Expect(deployment.Spec.Template.Spec.InitContainers[0].ImagePullPolicy).To(Equal(corev1.PullIfNotPresent))
+
+			By("reconciling again without changes")
+			generation := deployment.Generation
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Generation).To(Equal(generation))
+
+			By("changing the migration command")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.InitContainers[0].Command = []string{"migrate", "up", "1"}
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.InitContainers[0].Command).To(Equal([]string{"migrate", "up", "1"}))
+		})
+
+		It("should add and remove sidecars next to the application container", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("adding a logging sidecar")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.Sidecars = []corev1.Container{{Name: "fluent-bit", Image: "fluent/fluent-bit:3.0"}}
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			containers := deployment.Spec.Template.Spec.Containers
+			Expect(containers).To(HaveLen(2))
+			Expect(containers[0].Name).To(Equal(appContainerName))
+			Expect(containers[1].Name).To(Equal("fluent-bit"))
+
+			By("updating the application image with the sidecar in place")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.Image = "nginx:1.27"
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			containers = deployment.Spec.Template.Spec.Containers
+			Expect(containers[0].Image).To(Equal("nginx:1.27"))
+			Expect(containers[1].Image).To(Equal("fluent/fluent-bit:3.0"))
+
+			By("removing the sidecar")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.Sidecars = nil
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers).To(HaveLen(1))
+			Expect(deployment.Spec.Template.Spec.Containers[0].Name).To(Equal(appContainerName))
+		})
+
+		It("should rename the application container and find it by name", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.Sidecars = []corev1.Container{{Name: "fluent-bit", Image: "fluent/fluent-bit:3.0"}}
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("renaming the container")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.ContainerName = "web"
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			containers := deployment.Spec.Template.Spec.Containers
+			Expect(containers).To(HaveLen(2))
+			Expect(containers[0].Name).To(Equal("web"))
+			Expect(containers[1].Name).To(Equal("fluent-bit"))
+
+			By("updating the image after another controller reordered the containers")
+			deployment.Spec.Template.Spec.Containers = []corev1.Container{containers[1], containers[0]}
+			Expect(k8sClient.Update(ctx, deployment)).To(Succeed())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.Image = "nginx:1.27"
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			containers = deployment.Spec.Template.Spec.Containers
+			Expect(containers).To(HaveLen(2))
+			Expect(containers[0].Name).To(Equal("web"))
+			Expect(containers[0].Image).To(Equal("nginx:1.27"))
+			Expect(containers[1].Name).To(Equal("fluent-bit"))
+			Expect(containers[1].Image).To(Equal("fluent/fluent-bit:3.0"))
+		})
+
+		It("should apply the pod and container security contexts", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.PodSecurityContext = &corev1.PodSecurityContext{
+				RunAsNonRoot:   ptr.To(true),
+				SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+			}
+			simpleapp.Spec.SecurityContext = &corev1.SecurityContext{
+				AllowPrivilegeEscalation: ptr.To(false),
+				Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+			}
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.SecurityContext.RunAsNonRoot).To(HaveValue(BeTrue()))
+			container := deployment.Spec.Template.Spec.Containers[0]
+			Expect(container.SecurityContext.AllowPrivilegeEscalation).To(HaveValue(BeFalse()))
+			Expect(container.SecurityContext.Capabilities.Drop).To(ConsistOf(corev1.Capability("ALL")))
+
+			By("removing the contexts again")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.PodSecurityContext = nil
+			simpleapp.Spec.SecurityContext = nil
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.SecurityContext.RunAsNonRoot).To(BeNil())
+			Expect(deployment.Spec.Template.Spec.Containers[0].SecurityContext).To(BeNil())
+		})
+
+		It("should apply node scheduling constraints", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.NodeSelector).To(BeEmpty())
+			Expect(deployment.Spec.Template.Spec.Tolerations).To(BeEmpty())
+			Expect(deployment.Spec.Template.Spec.Affinity).To(BeNil())
+
+			By("pinning the app to a GPU node pool")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.NodeSelector = map[string]string{"pool": "gpu"}
+			simpleapp.Spec.Tolerations = []corev1.Toleration{{
+				Key:      "nvidia.com/gpu",
+				Operator: corev1.TolerationOpExists,
+				Effect:   corev1.TaintEffectNoSchedule,
+			}}
+			simpleapp.Spec.Affinity = &corev1.Affinity{
+				NodeAffinity: &corev1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+						NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+							MatchExpressions: []corev1.NodeSelectorRequirement{{
+								Key:      "kubernetes.io/arch",
+								Operator: corev1.NodeSelectorOpIn,
+								Values:   []string{"amd64"},
+							}},
+						}},
+					},
+				},
+			}
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			podSpec := deployment.Spec.Template.Spec
+			Expect(podSpec.NodeSelector).To(HaveKeyWithValue("pool", "gpu"))
+			Expect(podSpec.Tolerations).To(HaveLen(1))
+			Expect(podSpec.Tolerations[0].Key).To(Equal("nvidia.com/gpu"))
+			Expect(podSpec.Affinity.NodeAffinity).NotTo(BeNil())
+
+			By("removing the constraints again")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.NodeSelector = nil
+			simpleapp.Spec.Tolerations = nil
+			simpleapp.Spec.Affinity = nil
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.NodeSelector).To(BeEmpty())
+			Expect(deployment.Spec.Template.Spec.Tolerations).To(BeEmpty())
+			Expect(deployment.Spec.Template.Spec.Affinity).To(BeNil())
+		})
+
+		It("should pin the pods to spec.nodeArch", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.NodeArch = "arm64"
+			simpleapp.Spec.NodeSelector = map[string]string{"pool": "general"}
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.NodeSelector).To(Equal(map[string]string{
+				"pool": "general", "kubernetes.io/arch": "arm64"}))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(simpleapp.Spec.NodeSelector).NotTo(HaveKey("kubernetes.io/arch"), "the spec is left alone")
+
+			By("switching the architecture")
+			simpleapp.Spec.NodeArch = "amd64"
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.NodeSelector).To(HaveKeyWithValue("kubernetes.io/arch", "amd64"))
+
+			By("unpinning it")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.NodeArch = ""
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.NodeSelector).To(Equal(map[string]string{"pool": "general"}))
+		})
+
+		It("should spread the pods with topology spread constraints", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{{
+				MaxSkew:           1,
+				TopologyKey:       "topology.kubernetes.io/zone",
+				WhenUnsatisfiable: corev1.ScheduleAnyway,
+			}}
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			constraints := deployment.Spec.Template.Spec.TopologySpreadConstraints
+			Expect(constraints).To(HaveLen(1))
+			Expect(constraints[0].TopologyKey).To(Equal("topology.kubernetes.io/zone"))
+			Expect(constraints[0].LabelSelector).NotTo(BeNil())
+			Expect(constraints[0].LabelSelector.MatchLabels).To(Equal(deployment.Spec.Selector.MatchLabels))
+
+			By("reconciling again without changes")
+			generation := deployment.Generation
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Generation).To(Equal(generation))
+
+			By("removing the constraints")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.TopologySpreadConstraints = nil
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.TopologySpreadConstraints).To(BeEmpty())
+		})
+
+		It("should manage a PodDisruptionBudget when configured", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			pdb := &policyv1.PodDisruptionBudget{}
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, typeNamespacedName, pdb))).To(BeTrue())
+
+			By("requiring one pod to stay available")
+			minAvailable := intstr.FromInt32(1)
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.PodDisruptionBudget = &appsv1.PodDisruptionBudgetSpec{MinAvailable: &minAvailable}
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, pdb)).To(Succeed())
+			Expect(pdb.Spec.MinAvailable).To(HaveValue(Equal(minAvailable)))
+			Expect(pdb.Spec.Selector.MatchLabels).To(Equal(map[string]string{"app": resourceName}))
+			Expect(pdb.Labels).To(HaveKeyWithValue("app", resourceName))
+			Expect(metav1.IsControlledBy(pdb, simpleapp)).To(BeTrue())
+
+			By("switching to maxUnavailable")
+			maxUnavailable := intstr.FromString("25%")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.PodDisruptionBudget = &appsv1.PodDisruptionBudgetSpec{MaxUnavailable: &maxUnavailable}
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, pdb)).To(Succeed())
+			Expect(pdb.Spec.MinAvailable).To(BeNil())
+			Expect(pdb.Spec.MaxUnavailable).To(HaveValue(Equal(maxUnavailable)))
+
+			By("removing the block")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.PodDisruptionBudget = nil
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, typeNamespacedName, pdb))).To(BeTrue())
+		})
+
+		It("should warn when the PodDisruptionBudget blocks all disruptions", func() {
+			recorder := record.NewFakeRecorder(100)
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
+			}
+
+			minAvailable := intstr.FromInt32(1)
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.PodDisruptionBudget = &appsv1.PodDisruptionBudgetSpec{MinAvailable: &minAvailable}
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).To(Receive(ContainSubstring("PodDisruptionBudgetBlocksDisruptions")))
+
+			By("scaling up so one pod may be evicted")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.Replicas = ptr.To[int32](2)
+			maxUnavailable := intstr.FromInt32(1)
+			simpleapp.Spec.PodDisruptionBudget = &appsv1.PodDisruptionBudgetSpec{MaxUnavailable: &maxUnavailable}
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).NotTo(Receive(ContainSubstring("PodDisruptionBudgetBlocksDisruptions")))
+		})
+
+		It("should scale on the CPU of the target container and leave replicas to the HPA", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.Autoscaling = &appsv1.AutoscalingSpec{MaxReplicas: 5, TargetCPUUtilizationPercentage: 70}
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, hpa)).To(Succeed())
+			Expect(hpa.Labels).To(HaveKeyWithValue("app", resourceName))
+			Expect(hpa.Spec.ScaleTargetRef.Name).To(Equal(resourceName))
+			Expect(hpa.Spec.MaxReplicas).To(Equal(int32(5)))
+			Expect(hpa.Spec.Metrics).To(HaveLen(1))
+			Expect(hpa.Spec.Metrics[0].Type).To(Equal(autoscalingv2.ContainerResourceMetricSourceType))
+			Expect(hpa.Spec.Metrics[0].ContainerResource.Name).To(Equal(corev1.ResourceCPU))
+			Expect(hpa.Spec.Metrics[0].ContainerResource.Container).To(Equal("app"))
+			Expect(hpa.Spec.Metrics[0].ContainerResource.Target.AverageUtilization).To(HaveValue(Equal(int32(70))))
+
+			By("letting the HPA scale the Deployment")
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			deployment.Spec.Replicas = ptr.To(int32(4))
+			Expect(k8sClient.Update(ctx, deployment)).To(Succeed())
+
+			By("targeting a named container")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.Autoscaling.ScaleTargetContainer = "web"
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, hpa)).To(Succeed())
+			Expect(hpa.Spec.Metrics[0].ContainerResource.Container).To(Equal("web"))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Replicas).To(HaveValue(Equal(int32(4))))
+
+			By("removing the app label from the HPA by hand")
+			delete(hpa.Labels, "app")
+			Expect(k8sClient.Update(ctx, hpa)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, hpa)).To(Succeed())
+			Expect(hpa.Labels).To(HaveKeyWithValue("app", resourceName))
+
+			By("disabling autoscaling")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.Autoscaling = nil
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, typeNamespacedName, hpa))).To(BeTrue())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Replicas).To(HaveValue(Equal(int32(1))))
+		})
+
+		DescribeTable("should revert manual scaling unless allowManualScaling is set",
+			func(allowManualScaling bool, expected int32) {
+				controllerReconciler := &SimpleAppReconciler{
+					Client:   k8sClient,
+					Scheme:   k8sClient.Scheme(),
+					Recorder: record.NewFakeRecorder(100),
+				}
+				Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+				simpleapp.Spec.AllowManualScaling = allowManualScaling
+				simpleapp.Spec.Replicas = ptr.To(int32(2))
+				Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+				deployment := &k8sappsv1.Deployment{}
+				Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+				Expect(deployment.Spec.Replicas).To(HaveValue(Equal(int32(2))))
+
+				By("scaling the Deployment by hand, as kubectl scale does")
+				deployment.Spec.Replicas = ptr.To(int32(5))
+				Expect(k8sClient.Update(ctx, deployment)).To(Succeed())
+
+				By("changing the image, which still rolls out")
+				Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+				simpleapp.Spec.Image = "nginx:1.27"
+				Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+				_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+				Expect(deployment.Spec.Replicas).To(HaveValue(Equal(expected)))
+				Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("nginx:1.27"))
+			},
+			Entry("by default", false, int32(2)),
+			Entry("with allowManualScaling", true, int32(5)),
+		)
+
+		It("should report a rollout that exceeded its progress deadline", func() {
+			recorder := record.NewFakeRecorder(100)
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
+			}
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.ProgressDeadlineSeconds = ptr.To(int32(120))
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.ProgressDeadlineSeconds).To(HaveValue(Equal(int32(120))))
+
+			By("simulating an exceeded deadline")
+			deployment.Status.Conditions = []k8sappsv1.DeploymentCondition{{
+				Type:    k8sappsv1.DeploymentProgressing,
+				Status:  corev1.ConditionFalse,
+				Reason:  "ProgressDeadlineExceeded",
+				Message: `ReplicaSet "test-resource-5d4f" has timed out progressing.`,
+			}}
+			Expect(k8sClient.Status().Update(ctx, deployment)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(simpleapp.Status.ServiceStatus).To(Equal(appsv1.ServiceStatusFailed))
+			Expect(simpleapp.Status.Message).To(ContainSubstring("has timed out progressing"))
+			ready := meta.FindStatusCondition(simpleapp.Status.Conditions, appsv1.ConditionReady)
+			Expect(ready).NotTo(BeNil())
+			Expect(ready.Reason).To(Equal("ProgressDeadlineExceeded"))
+			Expect(recorder.Events).To(Receive(And(ContainSubstring("Warning"), ContainSubstring("ProgressDeadlineExceeded"))))
+
+			By("reconciling the stuck rollout again")
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).NotTo(Receive(), "the warning is only emitted once")
+
+			By("recovering with a new rollout")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			deployment.Status.Conditions[0].Status = corev1.ConditionTrue
+			deployment.Status.Conditions[0].Reason = "NewReplicaSetAvailable"
+			Expect(k8sClient.Status().Update(ctx, deployment)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(simpleapp.Status.ServiceStatus).To(BeEmpty())
+		})
+
+		It("should roll a stuck image back to the last good one with spec.autoRollback", func() {
+			recorder := record.NewFakeRecorder(100)
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
+			}
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("completing the rollout of the first image")
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			deployment.Status.ObservedGeneration = deployment.Generation
+			deployment.Status.Replicas = 1
+			deployment.Status.UpdatedReplicas = 1
+			deployment.Status.ReadyReplicas = 1
+			deployment.Status.AvailableReplicas = 1
+			Expect(k8sClient.Status().Update(ctx, deployment)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(simpleapp.Status.LastGoodImage).To(Equal("nginx:latest"))
+
+			By("rolling out an image that never becomes available")
+			simpleapp.Spec.AutoRollback = true
+			simpleapp.Spec.Image = "nginx:broken"
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("nginx:broken"))
+
+			By("simulating an exceeded deadline")
+			deployment.Status.ObservedGeneration = deployment.Generation
+			deployment.Status.UpdatedReplicas = 1
+			deployment.Status.AvailableReplicas = 0
+			deployment.Status.Conditions = []k8sappsv1.DeploymentCondition{{
+				Type:    k8sappsv1.DeploymentProgressing,
+				Status:  corev1.ConditionFalse,
+				Reason:  "ProgressDeadlineExceeded",
+				Message: `ReplicaSet "test-resource-7c9b" has timed out progressing.`,
+			}}
+			Expect(k8sClient.Status().Update(ctx, deployment)).To(Succeed())
+			for len(recorder.Events) > 0 {
+				<-recorder.Events
+			}
+			counting := &writeCountingClient{Client: k8sClient}
+			controllerReconciler.Client = counting
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(counting.writes).To(Equal(2), "one Deployment update and one status update")
+			controllerReconciler.Client = k8sClient
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("nginx:latest"))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(simpleapp.Status.FailedImage).To(Equal("nginx:broken"))
+			Expect(simpleapp.Status.LastGoodImage).To(Equal("nginx:latest"))
+			Expect(simpleapp.Status.RunningImage).To(Equal("nginx:latest"))
+			Expect(recorder.Events).To(Receive(And(ContainSubstring("Warning"), ContainSubstring("RolledBack"),
+				ContainSubstring("nginx:broken"))))
+
+			By("reconciling the rolled back app again")
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("nginx:latest"), "spec.image isn't retried")
+
+			By("fixing the image")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.Image = "nginx:1.27"
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("nginx:1.27"))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(simpleapp.Status.FailedImage).To(BeEmpty())
+		})
+
+		It("should notify when the app becomes Ready and when it degrades", func() {
+			server, received := notificationReceiver(http.StatusOK)
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+				Notifier: &Notifier{URL: server.URL},
+			}
+
+			By("reconciling a brand new app, which is not ready yet")
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(meta.IsStatusConditionFalse(simpleapp.Status.Conditions, appsv1.ConditionReady)).To(BeTrue())
+			Expect(received).NotTo(Receive())
+
+			setReadyReplicas := func(ready int32) {
+				deployment := &k8sappsv1.Deployment{}
+				Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+				deployment.Status.Replicas = 1
+				deployment.Status.ReadyReplicas = ready
+				Expect(k8sClient.Status().Update(ctx, deployment)).To(Succeed())
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			By("marking the pod ready")
+			setReadyReplicas(1)
+			var payload notificationPayload
+			Expect(received).To(Receive(&payload))
+			Expect(payload.Event).To(Equal(appsv1.NotificationReady))
+			Expect(payload.Name).To(Equal(resourceName))
+
+			By("reconciling again without changes")
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(received).NotTo(Receive())
+
+			By("losing the ready pod")
+			setReadyReplicas(0)
+			Expect(received).To(Receive(&payload))
+			Expect(payload.Event).To(Equal(appsv1.NotificationDegraded))
+		})
+
+		It("should not fail the reconcile when a notification cannot be delivered", func() {
+			server, _ := notificationReceiver(http.StatusInternalServerError)
+			recorder := record.NewFakeRecorder(100)
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
+				Notifier: &Notifier{URL: server.URL},
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			deployment.Status.Replicas = 1
+			deployment.Status.ReadyReplicas = 1
+			Expect(k8sClient.Status().Update(ctx, deployment)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).To(Receive(ContainSubstring("NotificationFailed")))
+		})
+
+		It("should report pods from other workloads that match the selector", func() {
+			recorder := record.NewFakeRecorder(100)
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
+			}
+
+			By("reconciling without foreign pods")
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(meta.IsStatusConditionFalse(simpleapp.Status.Conditions, appsv1.ConditionSelectorCollision)).To(BeTrue())
+
+			By("creating an unrelated pod carrying the app label")
+			foreign := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foreign-pod",
+					Namespace: "default",
+					Labels:    map[string]string{"app": resourceName},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "other", Image: "busybox"}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, foreign)).To(Succeed())
+			DeferCleanup(func() { Expect(k8sClient.Delete(ctx, foreign)).To(Succeed()) })
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			condition := meta.FindStatusCondition(simpleapp.Status.Conditions, appsv1.ConditionSelectorCollision)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Message).To(ContainSubstring("foreign-pod"))
+			Expect(recorder.Events).To(Receive(ContainSubstring("ForeignPodsSelected")))
+
+			By("not repeating the warning while the collision persists")
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).NotTo(Receive())
+		})
+
+		It("should report why the pods of a rollout are not coming up", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(simpleapp.Status.Message).To(BeEmpty())
+
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+
+			By("creating a pod of the Deployment that cannot pull its image")
+			replicaSet := &k8sappsv1.ReplicaSet{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName + "-abc", Namespace: "default", Labels: deployment.Spec.Template.Labels},
+				Spec: k8sappsv1.ReplicaSetSpec{
+					Replicas: ptr.To[int32](0),
+					Selector: deployment.Spec.Selector,
+					Template: deployment.Spec.Template,
+				},
+			}
+			Expect(controllerutil.SetControllerReference(deployment, replicaSet, k8sClient.Scheme())).To(Succeed())
+			Expect(k8sClient.Create(ctx, replicaSet)).To(Succeed())
+			DeferCleanup(func() { Expect(k8sClient.Delete(ctx, replicaSet)).To(Succeed()) })
+
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName + "-abc-xyz", Namespace: "default", Labels: deployment.Spec.Template.Labels},
+				Spec:       deployment.Spec.Template.Spec,
+			}
+			Expect(controllerutil.SetControllerReference(replicaSet, pod, k8sClient.Scheme())).To(Succeed())
+			Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+			DeferCleanup(func() { Expect(k8sClient.Delete(ctx, pod)).To(Succeed()) })
+			pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+				Name:  appContainerName,
+				Image: simpleapp.Spec.Image,
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}},
+			}}
+			Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+
+			deployment.Status.Replicas = 1
+			deployment.Status.UpdatedReplicas = 1
+			deployment.Status.UnavailableReplicas = 1
+			Expect(k8sClient.Status().Update(ctx, deployment)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(simpleapp.Status.UnavailableReplicas).To(Equal(int32(1)))
+			Expect(simpleapp.Status.UpdatedReplicas).To(Equal(int32(1)))
+			Expect(simpleapp.Status.Message).To(Equal("1 pod(s) ImagePullBackOff"))
+			Expect(meta.IsStatusConditionFalse(simpleapp.Status.Conditions, appsv1.ConditionSelectorCollision)).To(BeTrue())
+		})
+
+		It("should report the restarts of the app's pods and keep the count fresh", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeZero())
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+
+			By("creating a crash-looping pod of the Deployment")
+			replicaSet := &k8sappsv1.ReplicaSet{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName + "-abc", Namespace: "default", Labels: deployment.Spec.Template.Labels},
+				Spec: k8sappsv1.ReplicaSetSpec{
+					Replicas: ptr.To[int32](0),
+					Selector: deployment.Spec.Selector,
+					Template: deployment.Spec.Template,
+				},
+			}
+			Expect(controllerutil.SetControllerReference(deployment, replicaSet, k8sClient.Scheme())).To(Succeed())
+			Expect(k8sClient.Create(ctx, replicaSet)).To(Succeed())
+			DeferCleanup(func() { Expect(k8sClient.Delete(ctx, replicaSet)).To(Succeed()) })
+
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName + "-abc-xyz", Namespace: "default", Labels: deployment.Spec.Template.Labels},
+				Spec:       deployment.Spec.Template.Spec,
+			}
+			Expect(controllerutil.SetControllerReference(replicaSet, pod, k8sClient.Scheme())).To(Succeed())
+			Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+			DeferCleanup(func() { Expect(k8sClient.Delete(ctx, pod)).To(Succeed()) })
+			pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+				{Name: appContainerName, Image: simpleapp.Spec.Image, RestartCount: 3},
+				{Name: "log-shipper", Image: "fluent-bit:3", RestartCount: 1},
+			}
+			Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+
+			result, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(simpleapp.Status.TotalRestarts).To(Equal(int32(4)))
+			Expect(result.RequeueAfter).To(Equal(time.Minute))
+		})
+
+		It("should move the children when the nameTemplate changes", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, &k8sappsv1.Deployment{})).To(Succeed())
+
+			By("switching to a templated name")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.NameTemplate = "{{ .Name }}-v2"
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			renamed := types.NamespacedName{Name: resourceName + "-v2", Namespace: "default"}
+			Expect(k8sClient.Get(ctx, renamed, &k8sappsv1.Deployment{})).To(Succeed())
+			Expect(k8sClient.Get(ctx, renamed, &corev1.Service{})).To(Succeed())
+
+			By("checking the children under the old name were pruned")
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, typeNamespacedName, &k8sappsv1.Deployment{}))).To(BeTrue())
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, typeNamespacedName, &corev1.Service{}))).To(BeTrue())
+		})
+
+		It("should only list the children of the SimpleApp when pruning", func() {
+			recording := &listRecordingClient{Client: k8sClient}
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   recording,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recording.selectors).To(HaveKeyWithValue("DeploymentList", "app="+resourceName))
+			Expect(recording.selectors).To(HaveKeyWithValue("ServiceList", "app="+resourceName))
+			Expect(recording.selectors).To(HaveKeyWithValue("HorizontalPodAutoscalerList", "app="+resourceName))
+			Expect(recording.selectors).To(HaveKeyWithValue("NetworkPolicyList", "app="+resourceName))
+		})
+
+		It("should adopt the Deployment of a deleted SimpleApp with the same name", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("pointing the children at a previous incarnation of the app")
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			deployment.OwnerReferences[0].UID = "previous-uid"
+			Expect(k8sClient.Update(ctx, deployment)).To(Succeed())
+			service := &corev1.Service{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			service.OwnerReferences[0].UID = "previous-uid"
+			Expect(k8sClient.Update(ctx, service)).To(Succeed())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.Image = "nginx:1.27"
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			var deployments k8sappsv1.DeploymentList
+			Expect(k8sClient.List(ctx, &deployments, client.InNamespace("default"))).To(Succeed())
+			Expect(deployments.Items).To(HaveLen(1))
+			Expect(metav1.IsControlledBy(&deployments.Items[0], simpleapp)).To(BeTrue())
+			Expect(deployments.Items[0].OwnerReferences).To(HaveLen(1))
+			Expect(deployments.Items[0].Spec.Template.Spec.Containers[0].Image).To(Equal("nginx:1.27"))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			Expect(metav1.IsControlledBy(service, simpleapp)).To(BeTrue())
+		})
+
+		It("should adopt a Deployment and Service created without an owner", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			By("creating the children the way a raw manifest would")
+			labels := map[string]string{"app": resourceName}
+			deployment := &k8sappsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: k8sappsv1.DeploymentSpec{
+					Replicas: ptr.To[int32](1),
+					Selector: &metav1.LabelSelector{MatchLabels: labels},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: labels},
+						Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: "nginx:1.25"}}},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, deployment)).To(Succeed())
+			service := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec:       corev1.ServiceSpec{Selector: labels, Ports: []corev1.ServicePort{{Port: 80}}},
+			}
+			Expect(k8sClient.Create(ctx, service)).To(Succeed())
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(metav1.IsControlledBy(deployment, simpleapp)).To(BeTrue())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("nginx:latest"))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			Expect(metav1.IsControlledBy(service, simpleapp)).To(BeTrue())
+		})
+
+		It("should revert out-of-band edits of the managed fields", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Annotations).To(HaveKey("simpleapp.myapp.io/spec-hash"))
+
+			By("editing the Deployment and Service by hand")
+			deployment.Spec.Template.Spec.Containers[0].Image = "nginx:hacked"
+			deployment.Spec.Template.Spec.Containers[0].Ports[0].ContainerPort = 9090
+			Expect(k8sClient.Update(ctx, deployment)).To(Succeed())
+			service := &corev1.Service{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			service.Spec.Selector = map[string]string{"app": "something-else"}
+			service.Spec.Ports[0].Port = 8081
+			Expect(k8sClient.Update(ctx, service)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("nginx:latest"))
+			Expect(deployment.Spec.Template.Spec.Containers[0].Ports[0].ContainerPort).To(Equal(int32(80)))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			Expect(service.Spec.Selector).To(Equal(map[string]string{"app": resourceName}))
+			Expect(service.Spec.Ports[0].Port).To(Equal(int32(80)))
+		})
+
+		It("should retry the Deployment patch when it conflicts with a concurrent update", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("changing the image while another writer modifies the Deployment")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.Image = "nginx:1.27"
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			conflicting := &conflictingClient{Client: k8sClient}
+			controllerReconciler.Client = conflicting
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(conflicting.patches).To(Equal(2))
+
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("nginx:1.27"))
+			Expect(deployment.Annotations).To(HaveKeyWithValue("example.com/touched", "true"))
+		})
+
+		It("should converge when retried after a failed status update", func() {
+			recorder := record.NewFakeRecorder(100)
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
+			}
+			events := func() []string {
+				var received []string
+				for len(recorder.Events) > 0 {
+					received = append(received, <-recorder.Events)
+				}
+				return received
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			events()
+
+			By("changing the spec while the status can't be written")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.Image = "nginx:1.27"
+			simpleapp.Spec.EnvFromSecret = []string{"missing-credentials"}
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+			generation := simpleapp.Generation
+
+			controllerReconciler.Client = &failingStatusClient{Client: k8sClient, failures: 1}
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(errors.IsConflict(err)).To(BeTrue())
+			Expect(events()).To(ContainElement(ContainSubstring("SecretNotFound")))
+
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("nginx:1.27"))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(simpleapp.Status.ObservedGeneration).To(BeNumerically("<", generation))
+
+			By("retrying")
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events()).To(ContainElement(ContainSubstring("SecretNotFound")), "the generation wasn't reconciled yet")
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(simpleapp.Status.ObservedGeneration).To(Equal(generation))
+			Expect(simpleapp.Status.RunningImage).To(Equal("nginx:1.27"))
+			Expect(simpleapp.Status.Message).To(Equal("Rolling out image nginx:1.27"))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("nginx:1.27"))
+			Expect(deployment.Spec.Template.Spec.Containers[0].EnvFrom).To(HaveLen(1))
+
+			By("resyncing the reconciled generation")
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events()).To(BeEmpty(), "missing references are only reported once")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(meta.FindStatusCondition(simpleapp.Status.Conditions, appsv1.ConditionReferencesMissing)).To(
+				HaveField("Message", ContainSubstring(`Secret "missing-credentials"`)))
+		})
+
+		It("should warn about a referenced Secret deleted after the spec was reconciled", func() {
+			recorder := record.NewFakeRecorder(100)
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
+			}
+			events := func() []string {
+				var received []string
+				for len(recorder.Events) > 0 {
+					received = append(received, <-recorder.Events)
+				}
+				return received
+			}
+
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "short-lived", Namespace: "default"}}
+			Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.EnvFromSecret = []string{"short-lived"}
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events()).NotTo(ContainElement(ContainSubstring("SecretNotFound")))
+
+			By("deleting the Secret without changing the spec")
+			Expect(k8sClient.Delete(ctx, secret)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events()).To(ContainElement(ContainSubstring("SecretNotFound")))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(meta.IsStatusConditionTrue(simpleapp.Status.Conditions, appsv1.ConditionReferencesMissing)).To(BeTrue())
+
+			By("recreating it")
+			secret.ResourceVersion = ""
+			Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+			DeferCleanup(func() { Expect(k8sClient.Delete(ctx, secret)).To(Succeed()) })
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(meta.FindStatusCondition(simpleapp.Status.Conditions, appsv1.ConditionReferencesMissing)).To(BeNil())
+		})
+
+		It("should not recreate children that a lagging cache reports as missing", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("reconciling through a cache that hasn't seen the children yet")
+			stale := &staleCacheClient{Client: k8sClient}
+			controllerReconciler.Client = stale
+			controllerReconciler.APIReader = k8sClient
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stale.creates).To(BeZero())
+		})
+
+		It("should only confirm missing children with the API server before creating them", func() {
+			reader := &countingReader{Reader: k8sClient}
+			controllerReconciler := &SimpleAppReconciler{
+				Client:    k8sClient,
+				Scheme:    k8sClient.Scheme(),
+				Recorder:  record.NewFakeRecorder(100),
+				APIReader: reader,
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reader.gets).NotTo(BeZero())
+
+			By("reconciling again, with no child left to create")
+			reader.gets = 0
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reader.gets).To(BeZero(), "unset PodDisruptionBudget, HPA and NetworkPolicy are looked up in the cache only")
+		})
+
+		It("should record reconcile and child operation metrics", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+			successes := testutil.ToFloat64(metrics.ReconcileTotal.WithLabelValues(metrics.ResultSuccess))
+			deploymentCreates := testutil.ToFloat64(metrics.ChildOperationsTotal.WithLabelValues("Deployment", metrics.OperationCreate))
+			serviceCreates := testutil.ToFloat64(metrics.ChildOperationsTotal.WithLabelValues("Service", metrics.OperationCreate))
+			deploymentUpdates := testutil.ToFloat64(metrics.ChildOperationsTotal.WithLabelValues("Deployment", metrics.OperationUpdate))
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(testutil.ToFloat64(metrics.ReconcileTotal.WithLabelValues(metrics.ResultSuccess))).To(Equal(successes + 1))
+			Expect(testutil.ToFloat64(metrics.ChildOperationsTotal.WithLabelValues("Deployment", metrics.OperationCreate))).To(Equal(deploymentCreates + 1))
+			Expect(testutil.ToFloat64(metrics.ChildOperationsTotal.WithLabelValues("Service", metrics.OperationCreate))).To(Equal(serviceCreates + 1))
+
+			By("changing the image")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.Image = "nginx:1.27"
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(testutil.ToFloat64(metrics.ChildOperationsTotal.WithLabelValues("Deployment", metrics.OperationUpdate))).To(Equal(deploymentUpdates + 1))
+
+			By("adding and removing a PodDisruptionBudget")
+			pdbCreates := testutil.ToFloat64(metrics.ChildOperationsTotal.WithLabelValues("PodDisruptionBudget", metrics.OperationCreate))
+			pdbDeletes := testutil.ToFloat64(metrics.ChildOperationsTotal.WithLabelValues("PodDisruptionBudget", metrics.OperationDelete))
+			maxUnavailable := intstr.FromInt32(1)
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.PodDisruptionBudget = &appsv1.PodDisruptionBudgetSpec{MaxUnavailable: &maxUnavailable}
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(testutil.ToFloat64(metrics.ChildOperationsTotal.WithLabelValues("PodDisruptionBudget", metrics.OperationCreate))).To(Equal(pdbCreates + 1))
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.PodDisruptionBudget = nil
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(testutil.ToFloat64(metrics.ChildOperationsTotal.WithLabelValues("PodDisruptionBudget", metrics.OperationDelete))).To(Equal(pdbDeletes + 1))
+
+			By("adding and removing a HorizontalPodAutoscaler")
+			hpaCreates := testutil.ToFloat64(metrics.ChildOperationsTotal.WithLabelValues("HorizontalPodAutoscaler", metrics.OperationCreate))
+			hpaDeletes := testutil.ToFloat64(metrics.ChildOperationsTotal.WithLabelValues("HorizontalPodAutoscaler", metrics.OperationDelete))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.Autoscaling = &appsv1.AutoscalingSpec{MaxReplicas: 3}
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(testutil.ToFloat64(metrics.ChildOperationsTotal.WithLabelValues("HorizontalPodAutoscaler", metrics.OperationCreate))).To(Equal(hpaCreates + 1))
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.Autoscaling = nil
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(testutil.ToFloat64(metrics.ChildOperationsTotal.WithLabelValues("HorizontalPodAutoscaler", metrics.OperationDelete))).To(Equal(hpaDeletes + 1))
+
+			By("failing a reconcile")
+			failures := testutil.ToFloat64(metrics.ReconcileTotal.WithLabelValues(metrics.ResultError))
+			flaky := &flakyGetClient{Client: k8sClient, failing: &k8sappsv1.Deployment{}, failures: 1}
+			controllerReconciler.Client = flaky
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).To(HaveOccurred())
+			Expect(testutil.ToFloat64(metrics.ReconcileTotal.WithLabelValues(metrics.ResultError))).To(Equal(failures + 1))
+		})
+
+		It("should leave the children alone while suspended", func() {
+			counting := &writeCountingClient{Client: k8sClient}
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   counting,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("suspending the app and changing its image")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.Suspend = ptr.To(true)
+			simpleapp.Spec.Image = "nginx:1.27"
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			counting.writes = 0
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(counting.writes).To(Equal(1), "only the status is written")
+
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("nginx:latest"))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(simpleapp.Status.Message).To(Equal("Suspended"))
+
+			By("reconciling again while suspended")
+			counting.writes = 0
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(counting.writes).To(BeZero())
+
+			By("resuming the app")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.Suspend = ptr.To(false)
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("nginx:1.27"))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			Expect(simpleapp.Status.Message).NotTo(Equal("Suspended"))
+		})
+
+		It("should not write anything when reconciling an unchanged SimpleApp", func() {
+			counting := &writeCountingClient{Client: k8sClient}
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   counting,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(counting.writes).NotTo(BeZero())
+
+			service := &corev1.Service{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+			Expect(service.Annotations).To(HaveKey("simpleapp.myapp.io/spec-hash"))
+
+			By("reconciling again with the same spec")
+			counting.writes = 0
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(counting.writes).To(BeZero())
+		})
+
+		It("should migrate the spec hash from its previous annotation", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("moving the hash to the annotation of earlier versions")
+			deployment := &k8sappsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			deployment.Annotations["apps.myapp.io/spec-hash"] = deployment.Annotations["simpleapp.myapp.io/spec-hash"]
+			delete(deployment.Annotations, "simpleapp.myapp.io/spec-hash")
+			Expect(k8sClient.Update(ctx, deployment)).To(Succeed())
+			resourceVersion := deployment.ResourceVersion
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.ResourceVersion).To(Equal(resourceVersion))
+
+			By("changing the spec")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.Image = "nginx:1.27"
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Annotations).To(HaveKey("simpleapp.myapp.io/spec-hash"))
+			Expect(deployment.Annotations).NotTo(HaveKey("apps.myapp.io/spec-hash"))
+		})
+
+		It("should log why the Deployment and Service were updated", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			resource := &appsv1.SimpleApp{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			resource.Spec.Image = "nginx:1.27"
+			resource.Spec.ServicePort = 8080
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+			logger, lines := capturingLogger(1)
+			_, err = controllerReconciler.Reconcile(logf.IntoContext(ctx, logger), reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*lines).To(ContainElement(And(ContainSubstring("Updating Deployment"),
+				ContainSubstring(`"Namespace"="default"`), ContainSubstring(`"image"`))))
+			Expect(*lines).To(ContainElement(And(ContainSubstring("Updating Service"), ContainSubstring(`"ports"`))))
+			Expect(*lines).To(ContainElement(ContainSubstring("Updating SimpleApp status")))
+
+			By("reconciling again with the same spec")
+			logger, lines = capturingLogger(1)
+			_, err = controllerReconciler.Reconcile(logf.IntoContext(ctx, logger), reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*lines).To(ContainElement(ContainSubstring("Deployment up to date")))
+			Expect(*lines).To(ContainElement(ContainSubstring("Service up to date")))
+		})
+
+		DescribeTable("should recreate the Service only when an in-place update is rejected as invalid",
+			func(updateErr error, recreated bool) {
+				recorder := record.NewFakeRecorder(100)
+				controllerReconciler := &SimpleAppReconciler{
+					Client:   k8sClient,
+					Scheme:   k8sClient.Scheme(),
+					Recorder: recorder,
+				}
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+				simpleapp.Spec.ServicePort = 8080
+				Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+				controllerReconciler.Client = &rejectingServiceUpdateClient{Client: k8sClient, err: updateErr}
+				_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				service := &corev1.Service{}
+				Expect(k8sClient.Get(ctx, typeNamespacedName, service)).To(Succeed())
+				if recreated {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(service.Spec.Ports[0].Port).To(Equal(int32(8080)))
+					Expect(recorder.Events).To(Receive(ContainSubstring("ServiceRecreated")))
+				} else {
+					Expect(err).To(MatchError(updateErr))
+					Expect(service.Spec.Ports[0].Port).To(Equal(int32(80)))
+					Expect(recorder.Events).NotTo(Receive(ContainSubstring("ServiceRecreated")))
+				}
+			},
+			Entry("invalid", errors.NewInvalid(schema.GroupKind{Kind: "Service"}, resourceName, nil), true),
+			Entry("conflict", errors.NewConflict(corev1.Resource("services"), resourceName, nil), false),
+			Entry("forbidden", errors.NewForbidden(corev1.Resource("services"), resourceName, nil), false),
+		)
+
+		DescribeTable("should retry instead of creating a child when its Get fails transiently",
+			func(child client.Object) {
+				flaky := &flakyGetClient{Client: k8sClient, failing: child, failures: 1}
+				controllerReconciler := &SimpleAppReconciler{
+					Client:   flaky,
+					Scheme:   k8sClient.Scheme(),
+					Recorder: record.NewFakeRecorder(100),
+				}
+
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(errors.IsServerTimeout(err)).To(BeTrue(), "the error must reach controller-runtime so it requeues with backoff")
+				Expect(errors.IsNotFound(k8sClient.Get(ctx, typeNamespacedName, child))).To(BeTrue())
+				Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+				Expect(simpleapp.Status.Conditions).To(BeEmpty())
+
+				By("retrying once the API server recovers")
+				_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(k8sClient.Get(ctx, typeNamespacedName, child)).To(Succeed())
+				Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+				Expect(simpleapp.Status.Conditions).NotTo(BeEmpty())
+			},
+			Entry("Deployment", &k8sappsv1.Deployment{}),
+			Entry("Service", &corev1.Service{}),
+		)
+
+		It("should produce valid selector labels for dotted and long names", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			dotted := &appsv1.SimpleApp{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "shop.example.com-" + strings.Repeat("a", 60),
+					Namespace: "default",
+				},
+				Spec: appsv1.SimpleAppSpec{Image: "nginx:latest", ContainerPort: 80, Replicas: ptr.To[int32](1)},
+			}
+			Expect(k8sClient.Create(ctx, dotted)).To(Succeed())
+			DeferCleanup(func() {
+				deleteControlledChildren(ctx, dotted)
+				Expect(k8sClient.Delete(ctx, dotted)).To(Succeed())
+			})
+
+			deployment, err := controllerReconciler.ensureDeployment(ctx, dotted, dotted.Name, 1)
+			Expect(err).NotTo(HaveOccurred())
+
+			podLabels := deployment.Spec.Template.Labels
+			Expect(validation.IsValidLabelValue(podLabels["app"])).To(BeEmpty())
+			selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(selector.Matches(labels.Set(podLabels))).To(BeTrue())
+		})
+
+		It("should fail to reconcile when the nameTemplate renders an invalid name", func() {
+			controllerReconciler := &SimpleAppReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(100),
+			}
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, simpleapp)).To(Succeed())
+			simpleapp.Spec.NameTemplate = "{{ .Labels.env }}-{{ .Name }}"
+			Expect(k8sClient.Update(ctx, simpleapp)).To(Succeed())
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).To(MatchError(ContainSubstring("nameTemplate")))
+		})
+	})
+})
+
+var _ = Describe("serviceEndpoint", func() {
+	It("is unset without a Service", func() {
+		Expect(serviceEndpoint(nil)).To(BeNil())
+	})
+
+	It("reports the cluster IP and the app port", func() {
+		svc := &corev1.Service{Spec: corev1.ServiceSpec{
+			ClusterIP: "10.0.0.5",
+			Ports:     []corev1.ServicePort{{Port: 80}, {Name: "metrics", Port: 9090}},
+		}}
+		Expect(serviceEndpoint(svc)).To(Equal(&appsv1.ServiceEndpoint{ClusterIP: "10.0.0.5", Port: 80}))
+	})
+
+	It("reports the address of a provisioned load balancer", func() {
+		svc := &corev1.Service{
+			Spec: corev1.ServiceSpec{
+				Type:      corev1.ServiceTypeLoadBalancer,
+				ClusterIP: "10.0.0.5",
+				Ports:     []corev1.ServicePort{{Port: 443}},
+			},
+		}
+		Expect(serviceEndpoint(svc).ExternalAddress).To(BeEmpty())
+
+		svc.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{Hostname: "abc.elb.example.com"}}
+		Expect(serviceEndpoint(svc).ExternalAddress).To(Equal("abc.elb.example.com"))
+
+		svc.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "203.0.113.7"}}
+		Expect(serviceEndpoint(svc).ExternalAddress).To(Equal("203.0.113.7"))
+	})
+})
+
+var _ = Describe("rateLimiter", func() {
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "web", Namespace: "default"}}
+
+	It("should back off exponentially from the base to the max backoff", func() {
+		limiter := (&SimpleAppReconciler{BaseBackoff: time.Second, MaxBackoff: 5 * time.Second}).rateLimiter()
+		Expect(limiter.When(req)).To(Equal(time.Second))
+		Expect(limiter.When(req)).To(Equal(2 * time.Second))
+		Expect(limiter.When(req)).To(Equal(4 * time.Second))
+		Expect(limiter.When(req)).To(Equal(5 * time.Second))
+
+		By("starting over once the SimpleApp reconciles")
+		limiter.Forget(req)
+		Expect(limiter.When(req)).To(Equal(time.Second))
+	})
+
+	It("should keep the controller-runtime defaults when unset", func() {
+		limiter := (&SimpleAppReconciler{}).rateLimiter()
+		Expect(limiter.When(req)).To(Equal(5 * time.Millisecond))
+	})
+})
+
+var _ = Describe("requeueAfter", func() {
+	It("should spread resyncs over the jitter", func() {
+		r := &SimpleAppReconciler{ResyncPeriod: 10 * time.Minute, ResyncJitter: 0.1}
+		for range 20 {
+			Expect(r.requeueAfter(&appsv1.SimpleApp{})).To(And(
+				BeNumerically(">=", 10*time.Minute), BeNumerically("<", 11*time.Minute)))
+		}
+	})
+
+	It("should requeue apps with restarted containers every minute", func() {
+		app := &appsv1.SimpleApp{Status: appsv1.SimpleAppStatus{TotalRestarts: 2}}
+		Expect((&SimpleAppReconciler{}).requeueAfter(app)).To(Equal(time.Minute))
+		Expect((&SimpleAppReconciler{ResyncPeriod: 30 * time.Second}).requeueAfter(app)).To(Equal(30 * time.Second))
+	})
+
+	It("should not requeue without a resync period, whatever the jitter", func() {
+		r := &SimpleAppReconciler{ResyncJitter: 0.1}
+		Expect(r.requeueAfter(&appsv1.SimpleApp{})).To(BeZero())
+	})
+})
+
+// deleteControlledChildren removes the objects controlled by the SimpleApp, standing in
+// for the garbage collector that envtest does not run.
+func deleteControlledChildren(ctx context.Context, owner *appsv1.SimpleApp) {
+	var deployments k8sappsv1.DeploymentList
+	Expect(k8sClient.List(ctx, &deployments, client.InNamespace(owner.Namespace))).To(Succeed())
+	var services corev1.ServiceList
+	Expect(k8sClient.List(ctx, &services, client.InNamespace(owner.Namespace))).To(Succeed())
+	var pdbs policyv1.PodDisruptionBudgetList
+	Expect(k8sClient.List(ctx, &pdbs, client.InNamespace(owner.Namespace))).To(Succeed())
+	var hpas autoscalingv2.HorizontalPodAutoscalerList
+	Expect(k8sClient.List(ctx, &hpas, client.InNamespace(owner.Namespace))).To(Succeed())
+
+	var children []client.Object
+	for i := range deployments.Items {
+		children = append(children, &deployments.Items[i])
+	}
+	for i := range services.Items {
+		children = append(children, &services.Items[i])
+	}
+	for i := range pdbs.Items {
+		children = append(children, &pdbs.Items[i])
+	}
+	for i := range hpas.Items {
+		children = append(children, &hpas.Items[i])
+	}
+	for _, child := range children {
+		if metav1.IsControlledBy(child, owner) {
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, child))).To(Succeed())
+		}
+	}
+}
+
+// conflictingClient modifies a Deployment behind the reconciler's back right before its
+// first patch, so that the optimistic lock of that patch fails with a conflict.
+type conflictingClient struct {
+	client.Client
+	patches int
+}
+
+func (c *conflictingClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if _, ok := obj.(*k8sappsv1.Deployment); ok {
+		c.patches++
+		if c.patches == 1 {
+			var current k8sappsv1.Deployment
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(obj), &current)).To(Succeed())
+			if current.Annotations == nil {
+				current.Annotations = map[string]string{}
+			}
+			current.Annotations["example.com/touched"] = "true"
+			Expect(c.Update(ctx, &current)).To(Succeed())
+		}
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+// failingStatusClient fails the first status updates with a conflict, like a status written
+// concurrently by another reconcile.
+type failingStatusClient struct {
+	client.Client
+	failures int
+}
+
+func (c *failingStatusClient) Status() client.SubResourceWriter {
+	return &failingStatusWriter{SubResourceWriter: c.Client.Status(), failures: &c.failures}
+}
+
+type failingStatusWriter struct {
+	client.SubResourceWriter
+	failures *int
+}
+
+func (s *failingStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	if *s.failures > 0 {
+		*s.failures--
+		return errors.NewConflict(appsv1.GroupVersion.WithResource("simpleapps").GroupResource(), obj.GetName(),
+			fmt.Errorf("the object has been modified"))
+	}
+	return s.SubResourceWriter.Update(ctx, obj, opts...)
+}
+
+// staleCacheClient behaves like a cache that hasn't synced the Deployment and Service yet:
+// reads report them as not found, while writes go to the API server.
+type staleCacheClient struct {
+	client.Client
+	creates int
+}
+
+func (c *staleCacheClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	switch obj.(type) {
+	case *k8sappsv1.Deployment:
+		return errors.NewNotFound(k8sappsv1.Resource("deployments"), key.Name)
+	case *corev1.Service:
+		return errors.NewNotFound(corev1.Resource("services"), key.Name)
+	}
+	return c.Client.Get(ctx, key, obj, opts...)
+}
+
+func (c *staleCacheClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	c.creates++
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+// countingReader counts the reads of an uncached reader.
+type countingReader struct {
+	client.Reader
+	gets int
+}
+
+func (r *countingReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	r.gets++
+	return r.Reader.Get(ctx, key, obj, opts...)
+}
+
+// listRecordingClient records the label selector of the last List of each list type.
+type listRecordingClient struct {
+	client.Client
+	selectors map[string]string
+}
+
+func (c *listRecordingClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	listOpts := (&client.ListOptions{}).ApplyOptions(opts)
+	if c.selectors == nil {
+		c.selectors = map[string]string{}
+	}
+	c.selectors[reflect.TypeOf(list).Elem().Name()] = ""
+	if listOpts.LabelSelector != nil {
+		c.selectors[reflect.TypeOf(list).Elem().Name()] = listOpts.LabelSelector.String()
+	}
+	return c.Client.List(ctx, list, opts...)
+}
+
+// rejectingServiceUpdateClient fails every Service update with err, like an API server refusing a
+// change that can't be applied in place.
+type rejectingServiceUpdateClient struct {
+	client.Client
+	err error
+}
+
+func (c *rejectingServiceUpdateClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if _, ok := obj.(*corev1.Service); ok {
+		return c.err
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+// flakyGetClient fails the first reads of one kind of object with a server timeout,
+// like an API server that is briefly unavailable.
+type flakyGetClient struct {
+	client.Client
+	failing  client.Object
+	failures int
+}
+
+func (c *flakyGetClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	if c.failures > 0 && reflect.TypeOf(obj) == reflect.TypeOf(c.failing) {
+		c.failures--
+		return errors.NewServerTimeout(schema.GroupResource{}, "get", 1)
+	}
+	return c.Client.Get(ctx, key, obj, opts...)
+}
+
+// writeCountingClient counts the writes, including status writes, sent to the API server.
+type writeCountingClient struct {
+	client.Client
+	writes int
+}
+
+func (c *writeCountingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	c.writes++
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *writeCountingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	c.writes++
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func (c *writeCountingClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	c.writes++
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (c *writeCountingClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	c.writes++
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+func (c *writeCountingClient) Status() client.SubResourceWriter {
+	return &statusWriteCounter{SubResourceWriter: c.Client.Status(), writes: &c.writes}
+}
+
+type statusWriteCounter struct {
+	client.SubResourceWriter
+	writes *int
+}
+
+func (s *statusWriteCounter) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	*s.writes++
+	return s.SubResourceWriter.Update(ctx, obj, opts...)
+}
+
+func (s *statusWriteCounter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	*s.writes++
+	return s.SubResourceWriter.Patch(ctx, obj, patch, opts...)
+}
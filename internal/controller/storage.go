@@ -0,0 +1,114 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	appsv1alpha1 "github.com/gxanlvxgx/simple-app-operator/api/v1"
+	"github.com/gxanlvxgx/simple-app-operator/internal/metrics"
+)
+
+// storageVolumeName is the pod volume spec.storage is mounted through; spec.volumes can't use it.
+const storageVolumeName = "storage"
+
+// pvcName returns the name of the PersistentVolumeClaim provisioned for spec.storage.
+func pvcName(name string) string {
+	return name + "-data"
+}
+
+// storageVolume returns the pod volume and container mount of spec.storage.
+func storageVolume(cr *appsv1alpha1.SimpleApp, name string) (corev1.Volume, corev1.VolumeMount) {
+	volume := corev1.Volume{
+		Name: storageVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: pvcName(name)},
+		},
+	}
+	return volume, corev1.VolumeMount{Name: storageVolumeName, MountPath: cr.Spec.Storage.MountPath}
+}
+
+// ensurePVC creates the PersistentVolumeClaim of spec.storage and grows it when the requested size
+// increases. Claims can't shrink and their storage class is immutable, so neither is reconciled.
+// A claim is never deleted here, even once spec.storage is removed; it is garbage collected with
+// the SimpleApp.
+func (r *SimpleAppReconciler) ensurePVC(ctx context.Context, cr *appsv1alpha1.SimpleApp, name string) error {
+	if cr.Spec.Storage == nil {
+		return nil
+	}
+	log := logf.FromContext(ctx).WithValues("PersistentVolumeClaim", pvcName(name), "Namespace", cr.Namespace)
+
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pvcName(name),
+			Namespace: cr.Namespace,
+			Labels:    r.childLabels(cr),
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			StorageClassName: cr.Spec.Storage.StorageClassName,
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: cr.Spec.Storage.Size},
+			},
+		},
+	}
+	if err := ctrl.SetControllerReference(cr, pvc, r.Scheme); err != nil {
+		return err
+	}
+
+	var existing corev1.PersistentVolumeClaim
+	err := r.getChild(ctx, client.ObjectKeyFromObject(pvc), &existing)
+	if client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	if err != nil {
+		log.V(1).Info("Creating PersistentVolumeClaim")
+		if err := r.Create(ctx, pvc); err != nil {
+			return err
+		}
+		metrics.RecordChildOperation("PersistentVolumeClaim", metrics.OperationCreate)
+		return nil
+	}
+
+	current := existing.Spec.Resources.Requests[corev1.ResourceStorage]
+	if cr.Spec.Storage.Size.Cmp(current) <= 0 {
+		return nil
+	}
+	log.V(1).Info("Expanding PersistentVolumeClaim", "From", current.String(), "To", cr.Spec.Storage.Size.String())
+	if existing.Spec.Resources.Requests == nil {
+		existing.Spec.Resources.Requests = corev1.ResourceList{}
+	}
+	existing.Spec.Resources.Requests[corev1.ResourceStorage] = cr.Spec.Storage.Size
+	if err := r.Update(ctx, &existing); err != nil {
+		if !apierrors.IsForbidden(err) && !apierrors.IsInvalid(err) {
+			return err
+		}
+		// e.g. a storage class without allowVolumeExpansion; the pods keep running on the old size
+		r.Recorder.Eventf(cr, corev1.EventTypeWarning, "PersistentVolumeClaimExpansionFailed",
+			"Could not expand PersistentVolumeClaim %s to %s: %v", existing.Name, cr.Spec.Storage.Size.String(), err)
+		return nil
+	}
+	metrics.RecordChildOperation("PersistentVolumeClaim", metrics.OperationUpdate)
+	return nil
+}
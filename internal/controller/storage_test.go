@@ -0,0 +1,130 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	k8sappsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appsv1 "github.com/gxanlvxgx/simple-app-operator/api/v1"
+)
+
+var _ = Describe("Per-app storage", func() {
+	var (
+		reconciler *SimpleAppReconciler
+		app        *appsv1.SimpleApp
+		key        = client.ObjectKey{Name: "postgres", Namespace: "default"}
+		pvcKey     = client.ObjectKey{Name: "postgres-data", Namespace: "default"}
+	)
+
+	reconcileApp := func() {
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		ExpectWithOffset(1, reconciler.Get(ctx, key, app)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		reconciler = &SimpleAppReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).
+				WithStatusSubresource(&appsv1.SimpleApp{}, &k8sappsv1.Deployment{}).Build(),
+			Scheme:   scheme.Scheme,
+			Recorder: record.NewFakeRecorder(100),
+		}
+		app = &appsv1.SimpleApp{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+			Spec: appsv1.SimpleAppSpec{
+				Image:         "postgres:16",
+				Replicas:      ptr.To[int32](1),
+				ContainerPort: 5432,
+				Storage: &appsv1.StorageSpec{
+					Size:             resource.MustParse("10Gi"),
+					StorageClassName: ptr.To("fast"),
+					MountPath:        "/var/lib/postgresql/data",
+				},
+			},
+		}
+		Expect(reconciler.Create(ctx, app)).To(Succeed())
+	})
+
+	It("should provision a claim and mount it into the app container", func() {
+		reconcileApp()
+
+		pvc := &corev1.PersistentVolumeClaim{}
+		Expect(reconciler.Get(ctx, pvcKey, pvc)).To(Succeed())
+		Expect(metav1.IsControlledBy(pvc, app)).To(BeTrue())
+		Expect(pvc.Spec.AccessModes).To(ConsistOf(corev1.ReadWriteOnce))
+		Expect(pvc.Spec.StorageClassName).To(HaveValue(Equal("fast")))
+		Expect(pvc.Spec.Resources.Requests.Storage().String()).To(Equal("10Gi"))
+
+		deployment := &k8sappsv1.Deployment{}
+		Expect(reconciler.Get(ctx, key, deployment)).To(Succeed())
+		podSpec := deployment.Spec.Template.Spec
+		Expect(podSpec.Volumes).To(ContainElement(corev1.Volume{
+			Name: "storage",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "postgres-data"},
+			},
+		}))
+		Expect(podSpec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+			Name: "storage", MountPath: "/var/lib/postgresql/data",
+		}))
+	})
+
+	It("should grow the claim but never shrink it", func() {
+		reconcileApp()
+
+		By("requesting more space")
+		app.Spec.Storage.Size = resource.MustParse("20Gi")
+		Expect(reconciler.Update(ctx, app)).To(Succeed())
+		reconcileApp()
+		pvc := &corev1.PersistentVolumeClaim{}
+		Expect(reconciler.Get(ctx, pvcKey, pvc)).To(Succeed())
+		Expect(pvc.Spec.Resources.Requests.Storage().String()).To(Equal("20Gi"))
+
+		By("requesting less space, as without the validating webhook")
+		app.Spec.Storage.Size = resource.MustParse("5Gi")
+		Expect(reconciler.Update(ctx, app)).To(Succeed())
+		reconcileApp()
+		Expect(reconciler.Get(ctx, pvcKey, pvc)).To(Succeed())
+		Expect(pvc.Spec.Resources.Requests.Storage().String()).To(Equal("20Gi"))
+	})
+
+	It("should keep the claim but unmount it when storage is removed", func() {
+		reconcileApp()
+
+		app.Spec.Storage = nil
+		Expect(reconciler.Update(ctx, app)).To(Succeed())
+		reconcileApp()
+
+		Expect(reconciler.Get(ctx, pvcKey, &corev1.PersistentVolumeClaim{})).To(Succeed())
+		deployment := &k8sappsv1.Deployment{}
+		Expect(reconciler.Get(ctx, key, deployment)).To(Succeed())
+		Expect(deployment.Spec.Template.Spec.Volumes).To(BeEmpty())
+		Expect(deployment.Spec.Template.Spec.Containers[0].VolumeMounts).To(BeEmpty())
+	})
+})
@@ -0,0 +1,37 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LeaderCheck", func() {
+	It("reports not ready until the replica is elected", func() {
+		elected := make(chan struct{})
+		check := LeaderCheck(elected)
+		req := httptest.NewRequest("GET", "/readyz/leader", nil)
+
+		Expect(check(req)).To(MatchError("not the elected leader"))
+
+		close(elected)
+		Expect(check(req)).To(Succeed())
+	})
+})
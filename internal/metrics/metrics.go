@@ -0,0 +1,82 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics defines the Prometheus metrics of the SimpleApp controller. They are registered
+// with the controller-runtime registry, so the manager's metrics server exposes them.
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// ResultSuccess labels reconciles that returned no error.
+	ResultSuccess = "success"
+	// ResultError labels reconciles that returned an error.
+	ResultError = "error"
+)
+
+const (
+	// OperationCreate labels the creation of a child object.
+	OperationCreate = "create"
+	// OperationUpdate labels an update or patch of a child object.
+	OperationUpdate = "update"
+	// OperationDelete labels the deletion of a child object.
+	OperationDelete = "delete"
+)
+
+var (
+	// ReconcileTotal counts SimpleApp reconciles by result.
+	ReconcileTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "simpleapp_reconcile_total",
+		Help: "Number of SimpleApp reconciles, by result (success or error).",
+	}, []string{"result"})
+
+	// ReconcileDuration observes how long SimpleApp reconciles take.
+	ReconcileDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "simpleapp_reconcile_duration_seconds",
+		Help:    "Duration of SimpleApp reconciles in seconds.",
+		Buckets: prometheus.DefBuckets,
+	})
+
+	// ChildOperationsTotal counts the writes to the objects generated for SimpleApps.
+	ChildOperationsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "simpleapp_child_operations_total",
+		Help: "Number of create, update and delete operations on SimpleApp child objects, by kind.",
+	}, []string{"kind", "operation"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(ReconcileTotal, ReconcileDuration, ChildOperationsTotal)
+}
+
+// RecordReconcile records the outcome and duration of a reconcile that started at start.
+func RecordReconcile(start time.Time, err error) {
+	result := ResultSuccess
+	if err != nil {
+		result = ResultError
+	}
+	ReconcileTotal.WithLabelValues(result).Inc()
+	ReconcileDuration.Observe(time.Since(start).Seconds())
+}
+
+// RecordChildOperation records a successful write to a child object of the given kind.
+func RecordChildOperation(kind, operation string) {
+	ChildOperationsTotal.WithLabelValues(kind, operation).Inc()
+}
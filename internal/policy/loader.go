@@ -0,0 +1,130 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package policy loads the per-namespace SimpleApp policies shared by the webhook and the controller.
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	toolscache "k8s.io/client-go/tools/cache"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1 "github.com/gxanlvxgx/simple-app-operator/api/v1"
+)
+
+// Source returns the policy data of a namespace, i.e. the data of its PolicyConfigMapName ConfigMap.
+// A namespace without that ConfigMap has an empty policy.
+type Source interface {
+	Policy(ctx context.Context, namespace string) (map[string]string, error)
+}
+
+// Loader is a read-through cache of the policy ConfigMaps. Each namespace is read once, including
+// namespaces without a policy, and dropped from the cache when its ConfigMap changes.
+type Loader struct {
+	// Reader is used on cache misses.
+	Reader client.Reader
+
+	mu      sync.Mutex
+	entries map[string]map[string]string
+	// generation is bumped on each invalidation so a read racing with a change isn't cached
+	generation map[string]uint64
+}
+
+var _ Source = &Loader{}
+
+// Policy implements Source. Callers must not modify the returned map.
+func (l *Loader) Policy(ctx context.Context, namespace string) (map[string]string, error) {
+	l.mu.Lock()
+	data, ok := l.entries[namespace]
+	generation := l.generation[namespace]
+	l.mu.Unlock()
+	if ok {
+		return data, nil
+	}
+
+	var cm corev1.ConfigMap
+	err := l.Reader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: appsv1.PolicyConfigMapName}, &cm)
+	switch {
+	case apierrors.IsNotFound(err):
+		data = map[string]string{}
+	case err != nil:
+		return nil, fmt.Errorf("reading the policy of namespace %s: %w", namespace, err)
+	default:
+		data = cm.Data
+		if data == nil {
+			data = map[string]string{}
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.generation[namespace] == generation {
+		if l.entries == nil {
+			l.entries = map[string]map[string]string{}
+		}
+		l.entries[namespace] = data
+	}
+	return data, nil
+}
+
+// Invalidate drops the cached policy of a namespace.
+func (l *Loader) Invalidate(namespace string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.entries, namespace)
+	if l.generation == nil {
+		l.generation = map[string]uint64{}
+	}
+	l.generation[namespace]++
+}
+
+// SetupWithManager invalidates cached policies when their ConfigMap is created, updated or deleted,
+// using the manager's ConfigMap informer.
+func (l *Loader) SetupWithManager(mgr ctrl.Manager) error {
+	informer, err := mgr.GetCache().GetInformer(context.Background(), &corev1.ConfigMap{})
+	if err != nil {
+		return err
+	}
+	_, err = informer.AddEventHandler(l.eventHandler())
+	return err
+}
+
+func (l *Loader) eventHandler() toolscache.ResourceEventHandler {
+	return toolscache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj any) { l.invalidateFor(obj) },
+		UpdateFunc: func(_, obj any) { l.invalidateFor(obj) },
+		DeleteFunc: func(obj any) {
+			if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			l.invalidateFor(obj)
+		},
+	}
+}
+
+func (l *Loader) invalidateFor(obj any) {
+	cm, ok := obj.(*corev1.ConfigMap)
+	if !ok || cm.Name != appsv1.PolicyConfigMapName {
+		return
+	}
+	l.Invalidate(cm.Namespace)
+}
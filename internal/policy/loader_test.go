@@ -0,0 +1,160 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appsv1 "github.com/gxanlvxgx/simple-app-operator/api/v1"
+)
+
+// countingReader counts the reads that reach the underlying client.
+type countingReader struct {
+	client.Reader
+	gets int
+}
+
+func (c *countingReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	c.gets++
+	return c.Reader.Get(ctx, key, obj, opts...)
+}
+
+var _ = Describe("Loader", func() {
+	var (
+		ctx    = context.Background()
+		c      client.Client
+		reader *countingReader
+		loader *Loader
+	)
+
+	policyConfigMap := func(namespace, imagePolicy string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: appsv1.PolicyConfigMapName, Namespace: namespace},
+			Data:       map[string]string{appsv1.PolicyImagePolicyKey: imagePolicy},
+		}
+	}
+
+	BeforeEach(func() {
+		c = fake.NewClientBuilder().WithObjects(policyConfigMap("prod", appsv1.ImagePolicyDigestRequired)).Build()
+		reader = &countingReader{Reader: c}
+		loader = &Loader{Reader: reader}
+	})
+
+	It("should read a namespace policy once and serve it from the cache afterwards", func() {
+		for range 3 {
+			data, err := loader.Policy(ctx, "prod")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(HaveKeyWithValue(appsv1.PolicyImagePolicyKey, appsv1.ImagePolicyDigestRequired))
+		}
+		Expect(reader.gets).To(Equal(1))
+	})
+
+	It("should cache namespaces without a policy ConfigMap as an empty policy", func() {
+		for range 2 {
+			data, err := loader.Policy(ctx, "dev")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(BeEmpty())
+		}
+		Expect(reader.gets).To(Equal(1))
+	})
+
+	It("should reload a namespace policy after its ConfigMap changes", func() {
+		handler := loader.eventHandler()
+		_, err := loader.Policy(ctx, "prod")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = loader.Policy(ctx, "dev")
+		Expect(err).NotTo(HaveOccurred())
+
+		By("updating the policy of prod")
+		updated := policyConfigMap("prod", appsv1.ImagePolicyImmutableTag)
+		Expect(c.Update(ctx, updated)).To(Succeed())
+		handler.OnUpdate(policyConfigMap("prod", appsv1.ImagePolicyDigestRequired), updated)
+
+		data, err := loader.Policy(ctx, "prod")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(HaveKeyWithValue(appsv1.PolicyImagePolicyKey, appsv1.ImagePolicyImmutableTag))
+		Expect(reader.gets).To(Equal(3))
+
+		By("leaving the cached policy of other namespaces alone")
+		_, err = loader.Policy(ctx, "dev")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reader.gets).To(Equal(3))
+
+		By("creating a policy in a namespace that had none")
+		created := policyConfigMap("dev", appsv1.ImagePolicyDigestRequired)
+		Expect(c.Create(ctx, created)).To(Succeed())
+		handler.OnAdd(created, false)
+		data, err = loader.Policy(ctx, "dev")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(HaveKeyWithValue(appsv1.PolicyImagePolicyKey, appsv1.ImagePolicyDigestRequired))
+
+		By("deleting a policy, including through a tombstone")
+		Expect(c.Delete(ctx, created)).To(Succeed())
+		handler.OnDelete(toolscache.DeletedFinalStateUnknown{Key: "dev/" + appsv1.PolicyConfigMapName, Obj: created})
+		data, err = loader.Policy(ctx, "dev")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(BeEmpty())
+		Expect(reader.gets).To(Equal(5))
+	})
+
+	It("should ignore changes to other ConfigMaps", func() {
+		_, err := loader.Policy(ctx, "prod")
+		Expect(err).NotTo(HaveOccurred())
+
+		other := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "app-config", Namespace: "prod"}}
+		loader.eventHandler().OnAdd(other, false)
+
+		_, err = loader.Policy(ctx, "prod")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reader.gets).To(Equal(1))
+	})
+
+	It("should not cache a read that raced with an invalidation", func() {
+		racing := &invalidatingReader{Reader: c, loader: loader}
+		loader.Reader = racing
+
+		_, err := loader.Policy(ctx, "prod")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = loader.Policy(ctx, "prod")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(racing.gets).To(Equal(2))
+	})
+})
+
+// invalidatingReader invalidates the namespace being read on the first read, as if its
+// ConfigMap changed while the read was in flight.
+type invalidatingReader struct {
+	client.Reader
+	loader *Loader
+	gets   int
+}
+
+func (r *invalidatingReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	r.gets++
+	if r.gets == 1 {
+		r.loader.Invalidate(key.Namespace)
+	}
+	return r.Reader.Get(ctx, key, obj, opts...)
+}
@@ -0,0 +1,563 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	appsv1 "github.com/gxanlvxgx/simple-app-operator/api/v1"
+	"github.com/gxanlvxgx/simple-app-operator/internal/policy"
+)
+
+// nolint:unused
+// log is for logging in this package.
+var simpleapplog = logf.Log.WithName("simpleapp-resource")
+
+// SetupSimpleAppWebhookWithManager registers the webhook for SimpleApp in the manager.
+// policies may be nil, in which case namespace policy ConfigMaps are ignored. servicePortFollowsContainerPort
+// selects the default of an omitted spec.servicePort, see SimpleAppCustomDefaulter.
+func SetupSimpleAppWebhookWithManager(mgr ctrl.Manager, policies policy.Source, servicePortFollowsContainerPort bool) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&appsv1.SimpleApp{}).
+		WithDefaulter(&SimpleAppCustomDefaulter{
+			// Security-context defaults read the Namespace through the cache too
+			Reader:                          mgr.GetClient(),
+			ServicePortFollowsContainerPort: servicePortFollowsContainerPort,
+		}).
+		WithValidator(&SimpleAppCustomValidator{
+			// Image policies read the Namespace through the manager's cache, so admission doesn't cost an API request
+			Reader:                          mgr.GetClient(),
+			Policies:                        policies,
+			ServicePortFollowsContainerPort: servicePortFollowsContainerPort,
+		}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-apps-myapp-io-v1-simpleapp,mutating=true,failurePolicy=fail,sideEffects=None,groups=apps.myapp.io,resources=simpleapps,verbs=create;update,versions=v1,name=msimpleapp-v1.kb.io,admissionReviewVersions=v1
+
+// podSecurityEnforceLabel is the Pod Security Admission label selecting the standard a namespace enforces.
+const podSecurityEnforceLabel = "pod-security.kubernetes.io/enforce"
+
+// SimpleAppCustomDefaulter struct is responsible for setting default values on the custom resource of the
+// Kind SimpleApp when those are created or updated.
+type SimpleAppCustomDefaulter struct {
+	// Reader looks up the Namespace of the SimpleApp to find the Pod Security Standard it enforces.
+	// When nil, security contexts are not defaulted.
+	Reader client.Reader
+	// ServicePortFollowsContainerPort defaults an omitted spec.servicePort to spec.containerPort
+	// instead of DefaultServicePort.
+	ServicePortFollowsContainerPort bool
+}
+
+var _ webhook.CustomDefaulter = &SimpleAppCustomDefaulter{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the Kind SimpleApp.
+func (d *SimpleAppCustomDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	simpleapp, ok := obj.(*appsv1.SimpleApp)
+	if !ok {
+		return fmt.Errorf("expected a SimpleApp object but got %T", obj)
+	}
+	simpleapplog.Info("Defaulting for SimpleApp", "name", simpleapp.GetName())
+
+	if simpleapp.Spec.ServicePort == 0 {
+		simpleapp.Spec.ServicePort = servicePort(simpleapp, d.ServicePortFollowsContainerPort)
+	}
+
+	if simpleapp.Spec.Replicas == nil {
+		simpleapp.Spec.Replicas = ptr.To[int32](1)
+	}
+
+	simpleapp.Spec.Image = normalizeImage(simpleapp.Spec.Image)
+
+	return d.defaultSecurityContexts(ctx, simpleapp)
+}
+
+// defaultSecurityContexts fills in security contexts satisfying the restricted Pod Security Standard
+// when the namespace enforces it and the SimpleApp leaves them out. Contexts that are set are kept,
+// so a non-compliant one is still rejected for the pods, where the cause is visible.
+func (d *SimpleAppCustomDefaulter) defaultSecurityContexts(ctx context.Context, simpleapp *appsv1.SimpleApp) error {
+	if d.Reader == nil {
+		return nil
+	}
+	var ns corev1.Namespace
+	if err := d.Reader.Get(ctx, client.ObjectKey{Name: simpleapp.Namespace}, &ns); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if ns.Labels[podSecurityEnforceLabel] != "restricted" {
+		return nil
+	}
+
+	if simpleapp.Spec.PodSecurityContext == nil {
+		simpleapp.Spec.PodSecurityContext = &corev1.PodSecurityContext{
+			RunAsNonRoot:   ptr.To(true),
+			SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+		}
+	}
+	if simpleapp.Spec.SecurityContext == nil {
+		simpleapp.Spec.SecurityContext = &corev1.SecurityContext{
+			AllowPrivilegeEscalation: ptr.To(false),
+			Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+		}
+	}
+	return nil
+}
+
+// normalizeImage trims surrounding whitespace, lowercases the registry and repository, and
+// appends ":latest" when the reference has neither a tag nor a digest. The tag and the digest are
+// kept as written, since tags are case-sensitive, and no default registry (docker.io) or library/
+// prefix is added.
+func normalizeImage(image string) string {
+	image = strings.TrimSpace(image)
+	if image == "" {
+		return image
+	}
+
+	name, tag, digest := splitImage(image)
+	name = strings.ToLower(name)
+
+	switch {
+	case digest != "" && tag != "":
+		return name + ":" + tag + "@" + digest
+	case digest != "":
+		return name + "@" + digest
+	case tag != "":
+		return name + ":" + tag
+	default:
+		return name + ":latest"
+	}
+}
+
+// imageReferenceRegexp matches [registry[:port]/]repository[:tag][@digest], following the
+// grammar of the distribution reference library. Repository path components must be lowercase.
+var imageReferenceRegexp = func() *regexp.Regexp {
+	const (
+		domainComponent = `(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9])`
+		domain          = domainComponent + `(?:\.` + domainComponent + `)*(?::[0-9]+)?`
+		pathComponent   = `[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*`
+		tag             = `[\w][\w.-]{0,127}`
+		digest          = `[A-Za-z][A-Za-z0-9]*(?:[-_+.][A-Za-z][A-Za-z0-9]*)*:[0-9A-Fa-f]{32,}`
+	)
+	return regexp.MustCompile(`^(?:` + domain + `/)?` + pathComponent + `(?:/` + pathComponent + `)*` +
+		`(?::` + tag + `)?(?:@` + digest + `)?$`)
+}()
+
+// maxImageNameLength is the longest repository name (registry included) registries accept.
+const maxImageNameLength = 255
+
+// validateImageReference returns why image is not a valid reference, or "" when it is.
+func validateImageReference(image string) string {
+	if !imageReferenceRegexp.MatchString(image) {
+		return "must be a valid image reference such as nginx:1.25, registry.example.com:5000/team/app:v1 " +
+			"or nginx@sha256:<digest>"
+	}
+	if name, _, _ := splitImage(image); len(name) > maxImageNameLength {
+		return fmt.Sprintf("the repository name must not be longer than %d characters", maxImageNameLength)
+	}
+	return ""
+}
+
+// splitImage breaks an image reference into its repository, tag and digest parts.
+func splitImage(image string) (name, tag, digest string) {
+	name, digest, _ = strings.Cut(image, "@")
+	// A colon before the last slash belongs to a registry port, not to a tag
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, tag = name[:i], name[i+1:]
+	}
+	return name, tag, digest
+}
+
+// +kubebuilder:webhook:path=/validate-apps-myapp-io-v1-simpleapp,mutating=false,failurePolicy=fail,sideEffects=None,groups=apps.myapp.io,resources=simpleapps,verbs=create;update,versions=v1,name=vsimpleapp-v1.kb.io,admissionReviewVersions=v1
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+// SimpleAppCustomValidator struct is responsible for validating the SimpleApp resource
+// when it is created, updated, or deleted.
+type SimpleAppCustomValidator struct {
+	// Reader looks up the Namespace of the SimpleApp to find the image policies it opted into.
+	Reader client.Reader
+	// Policies provides the namespace policy ConfigMaps. Optional.
+	Policies policy.Source
+	// ServicePortFollowsContainerPort matches SimpleAppCustomDefaulter, for objects validated
+	// with an omitted spec.servicePort.
+	ServicePortFollowsContainerPort bool
+}
+
+var _ webhook.CustomValidator = &SimpleAppCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type SimpleApp.
+func (v *SimpleAppCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	simpleapp, ok := obj.(*appsv1.SimpleApp)
+	if !ok {
+		return nil, fmt.Errorf("expected a SimpleApp object but got %T", obj)
+	}
+	simpleapplog.Info("Validation for SimpleApp upon creation", "name", simpleapp.GetName())
+
+	warnings, err := v.Validate(simpleapp)
+	if err != nil {
+		return warnings, err
+	}
+	return warnings, v.validateImagePolicies(ctx, nil, simpleapp)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type SimpleApp.
+func (v *SimpleAppCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	simpleapp, ok := newObj.(*appsv1.SimpleApp)
+	if !ok {
+		return nil, fmt.Errorf("expected a SimpleApp object for the newObj but got %T", newObj)
+	}
+	old, ok := oldObj.(*appsv1.SimpleApp)
+	if !ok {
+		return nil, fmt.Errorf("expected a SimpleApp object for the oldObj but got %T", oldObj)
+	}
+	simpleapplog.Info("Validation for SimpleApp upon update", "name", simpleapp.GetName())
+
+	warnings, err := v.Validate(simpleapp)
+	if err != nil {
+		return warnings, err
+	}
+	if err := validateStorageUpdate(old, simpleapp); err != nil {
+		return warnings, err
+	}
+	if err := validateSelectorLabelsUpdate(old, simpleapp); err != nil {
+		return warnings, err
+	}
+	return warnings, v.validateImagePolicies(ctx, old, simpleapp)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type SimpleApp.
+func (v *SimpleAppCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// Validate runs the checks of the validating webhook that need neither the cluster nor the previous
+// version of the object, e.g. to lint manifests offline, so it doesn't use Reader or Policies. The
+// webhook runs them on every create and update, before the namespace image policies.
+func (v *SimpleAppCustomValidator) Validate(simpleapp *appsv1.SimpleApp) (admission.Warnings, error) {
+	port := servicePort(simpleapp, v.ServicePortFollowsContainerPort)
+	if err := validateReplicaSource(simpleapp); err != nil {
+		return nil, err
+	}
+	if err := validatePorts(simpleapp, port); err != nil {
+		return nil, err
+	}
+	if err := validateLabels(simpleapp); err != nil {
+		return nil, err
+	}
+	if err := validatePriorityClassName(simpleapp); err != nil {
+		return nil, err
+	}
+	if err := validateNodeArch(simpleapp); err != nil {
+		return nil, err
+	}
+	if err := validatePublishNotReadyAddresses(simpleapp); err != nil {
+		return nil, err
+	}
+	if err := validateImageReferences(simpleapp); err != nil {
+		return nil, err
+	}
+	return append(pdbWarnings(simpleapp), portWarnings(simpleapp, port)...), nil
+}
+
+// validateReplicaSource rejects following the node count together with autoscaling, as both
+// would keep overwriting the replica count set by the other.
+func validateReplicaSource(simpleapp *appsv1.SimpleApp) error {
+	if !simpleapp.Spec.ReplicasFromNodeCount || simpleapp.Spec.Autoscaling == nil {
+		return nil
+	}
+	return apierrors.NewInvalid(appsv1.GroupVersion.WithKind("SimpleApp").GroupKind(), simpleapp.Name,
+		field.ErrorList{field.Forbidden(field.NewPath("spec", "replicasFromNodeCount"),
+			"cannot be combined with spec.autoscaling, which owns the replica count")})
+}
+
+// validatePorts checks the port numbers, which the CRD schema bounds as well, and rejects
+// combinations the Service can't be built from.
+func validatePorts(simpleapp *appsv1.SimpleApp, port int32) error {
+	var errs field.ErrorList
+	path := field.NewPath("spec")
+	for _, msg := range validation.IsValidPortNum(int(simpleapp.Spec.ContainerPort)) {
+		errs = append(errs, field.Invalid(path.Child("containerPort"), simpleapp.Spec.ContainerPort, msg))
+	}
+	// Zero stands for the default port when the defaulting webhook didn't run
+	if simpleapp.Spec.ServicePort != 0 {
+		for _, msg := range validation.IsValidPortNum(int(simpleapp.Spec.ServicePort)) {
+			errs = append(errs, field.Invalid(path.Child("servicePort"), simpleapp.Spec.ServicePort, msg))
+		}
+	}
+	// A metrics port of its own is exposed next to servicePort, and Service ports must be unique
+	if metrics := simpleapp.Spec.Metrics; metrics != nil && metrics.Port != simpleapp.Spec.ContainerPort &&
+		metrics.Port == port {
+		errs = append(errs, field.Invalid(path.Child("metrics", "port"), metrics.Port,
+			fmt.Sprintf("must differ from spec.servicePort (%d), since the Service exposes both", port)))
+	}
+	if admin := simpleapp.Spec.AdminPort; admin != nil {
+		for _, msg := range validation.IsValidPortNum(int(*admin)) {
+			errs = append(errs, field.Invalid(path.Child("adminPort"), *admin, msg))
+		}
+		switch {
+		case *admin == simpleapp.Spec.ContainerPort:
+			errs = append(errs, field.Invalid(path.Child("adminPort"), *admin, "must differ from spec.containerPort"))
+		case *admin == port:
+			errs = append(errs, field.Invalid(path.Child("adminPort"), *admin,
+				fmt.Sprintf("must differ from spec.servicePort (%d), since the Service exposes both", port)))
+		case simpleapp.Spec.Metrics != nil && *admin == simpleapp.Spec.Metrics.Port:
+			errs = append(errs, field.Invalid(path.Child("adminPort"), *admin, "must differ from spec.metrics.port"))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(appsv1.GroupVersion.WithKind("SimpleApp").GroupKind(), simpleapp.Name, errs)
+}
+
+// validatePublishNotReadyAddresses only allows publishing pods that aren't ready on headless
+// Services: a cluster IP would route client traffic to pods that can't serve it yet.
+func validatePublishNotReadyAddresses(simpleapp *appsv1.SimpleApp) error {
+	if !ptr.Deref(simpleapp.Spec.PublishNotReadyAddresses, false) || simpleapp.Spec.Headless {
+		return nil
+	}
+	return apierrors.NewInvalid(appsv1.GroupVersion.WithKind("SimpleApp").GroupKind(), simpleapp.Name,
+		field.ErrorList{field.Forbidden(field.NewPath("spec", "publishNotReadyAddresses"),
+			"requires spec.headless, since a cluster IP would send traffic to pods that aren't ready")})
+}
+
+// portWarnings warns about a headless Service whose port differs from the container port: clients
+// resolve the pod IPs and connect to the container port directly, so servicePort is never used.
+func portWarnings(simpleapp *appsv1.SimpleApp, port int32) admission.Warnings {
+	if !simpleapp.Spec.Headless || port == simpleapp.Spec.ContainerPort {
+		return nil
+	}
+	return admission.Warnings{fmt.Sprintf(
+		"spec.servicePort %d differs from spec.containerPort %d, but clients of a headless Service connect to "+
+			"the pods on the container port directly; set servicePort to %d", port,
+		simpleapp.Spec.ContainerPort, simpleapp.Spec.ContainerPort)}
+}
+
+// servicePort returns the port exposed by the Service, which defaults to DefaultServicePort or,
+// with followsContainerPort, to the container port.
+func servicePort(simpleapp *appsv1.SimpleApp, followsContainerPort bool) int32 {
+	switch {
+	case simpleapp.Spec.ServicePort != 0:
+		return simpleapp.Spec.ServicePort
+	case followsContainerPort:
+		return simpleapp.Spec.ContainerPort
+	}
+	return appsv1.DefaultServicePort
+}
+
+// validateStorageUpdate rejects changes to spec.storage that the PersistentVolumeClaim can't follow:
+// claims never shrink and their storage class is immutable.
+func validateStorageUpdate(old, simpleapp *appsv1.SimpleApp) error {
+	if old.Spec.Storage == nil || simpleapp.Spec.Storage == nil {
+		return nil
+	}
+	var errs field.ErrorList
+	path := field.NewPath("spec", "storage")
+	if simpleapp.Spec.Storage.Size.Cmp(old.Spec.Storage.Size) < 0 {
+		errs = append(errs, field.Forbidden(path.Child("size"),
+			fmt.Sprintf("cannot shrink from %s to %s", old.Spec.Storage.Size.String(), simpleapp.Spec.Storage.Size.String())))
+	}
+	if ptr.Deref(simpleapp.Spec.Storage.StorageClassName, "") != ptr.Deref(old.Spec.Storage.StorageClassName, "") {
+		errs = append(errs, field.Forbidden(path.Child("storageClassName"), "is immutable once the claim exists"))
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(appsv1.GroupVersion.WithKind("SimpleApp").GroupKind(), simpleapp.Name, errs)
+}
+
+// validateLabels checks that spec.selectorLabels and spec.deploymentLabels are valid labels.
+func validateLabels(simpleapp *appsv1.SimpleApp) error {
+	errs := metav1validation.ValidateLabels(simpleapp.Spec.SelectorLabels, field.NewPath("spec", "selectorLabels"))
+	errs = append(errs, metav1validation.ValidateLabels(simpleapp.Spec.DeploymentLabels, field.NewPath("spec", "deploymentLabels"))...)
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(appsv1.GroupVersion.WithKind("SimpleApp").GroupKind(), simpleapp.Name, errs)
+}
+
+// validatePriorityClassName checks that spec.priorityClassName can name a PriorityClass, which the
+// CRD schema checks as well.
+func validatePriorityClassName(simpleapp *appsv1.SimpleApp) error {
+	name := simpleapp.Spec.PriorityClassName
+	if name == "" {
+		return nil
+	}
+	var errs field.ErrorList
+	for _, msg := range validation.IsDNS1123Subdomain(name) {
+		errs = append(errs, field.Invalid(field.NewPath("spec", "priorityClassName"), name, msg))
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(appsv1.GroupVersion.WithKind("SimpleApp").GroupKind(), simpleapp.Name, errs)
+}
+
+// nodeArches are the architectures spec.nodeArch accepts, like the CRD schema.
+var nodeArches = []string{"amd64", "arm64", "ppc64le", "s390x"}
+
+// validateNodeArch checks that spec.nodeArch is a known architecture that spec.nodeSelector doesn't
+// contradict, which the CRD schema checks as well.
+func validateNodeArch(simpleapp *appsv1.SimpleApp) error {
+	arch := simpleapp.Spec.NodeArch
+	if arch == "" {
+		return nil
+	}
+	var errs field.ErrorList
+	if !slices.Contains(nodeArches, arch) {
+		errs = append(errs, field.NotSupported(field.NewPath("spec", "nodeArch"), arch, nodeArches))
+	}
+	if selected, ok := simpleapp.Spec.NodeSelector[corev1.LabelArchStable]; ok && selected != arch {
+		errs = append(errs, field.Invalid(field.NewPath("spec", "nodeSelector").Key(corev1.LabelArchStable), selected,
+			fmt.Sprintf("contradicts spec.nodeArch %s", arch)))
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(appsv1.GroupVersion.WithKind("SimpleApp").GroupKind(), simpleapp.Name, errs)
+}
+
+// validateSelectorLabelsUpdate rejects changing spec.selectorLabels: they end up in the Deployment
+// selector, which is immutable.
+func validateSelectorLabelsUpdate(old, simpleapp *appsv1.SimpleApp) error {
+	if maps.Equal(old.Spec.SelectorLabels, simpleapp.Spec.SelectorLabels) {
+		return nil
+	}
+	return apierrors.NewInvalid(appsv1.GroupVersion.WithKind("SimpleApp").GroupKind(), simpleapp.Name,
+		field.ErrorList{field.Forbidden(field.NewPath("spec", "selectorLabels"), "is immutable, since Deployment selectors are")})
+}
+
+// pdbWarnings warns about a PodDisruptionBudget that would block every voluntary disruption.
+// Such a budget is legal, so the object is still admitted.
+func pdbWarnings(simpleapp *appsv1.SimpleApp) admission.Warnings {
+	pdb := simpleapp.Spec.PodDisruptionBudget
+	if pdb == nil || !pdb.BlocksDisruptions(ptr.Deref(simpleapp.Spec.Replicas, 1)) {
+		return nil
+	}
+	return admission.Warnings{fmt.Sprintf(
+		"spec.podDisruptionBudget allows no pod of the %d replicas to be evicted, so node drains will hang; "+
+			"increase spec.replicas or lower minAvailable / raise maxUnavailable", ptr.Deref(simpleapp.Spec.Replicas, 1))}
+}
+
+// imagePolicies returns the image policies of a namespace, from both its annotation
+// and its policy ConfigMap, without duplicates.
+func (v *SimpleAppCustomValidator) imagePolicies(ctx context.Context, namespace string) ([]string, error) {
+	var raw []string
+	var ns corev1.Namespace
+	if err := v.Reader.Get(ctx, client.ObjectKey{Name: namespace}, &ns); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+	} else {
+		raw = append(raw, strings.Split(ns.Annotations[appsv1.ImagePolicyAnnotation], ",")...)
+	}
+
+	if v.Policies != nil {
+		data, err := v.Policies.Policy(ctx, namespace)
+		if err != nil {
+			return nil, err
+		}
+		raw = append(raw, strings.Split(data[appsv1.PolicyImagePolicyKey], ",")...)
+	}
+
+	var policies []string
+	seen := map[string]bool{}
+	for _, p := range raw {
+		p = strings.TrimSpace(p)
+		if p != "" && !seen[p] {
+			seen[p] = true
+			policies = append(policies, p)
+		}
+	}
+	return policies, nil
+}
+
+// validateImageReferences rejects malformed image references.
+func validateImageReferences(simpleapp *appsv1.SimpleApp) error {
+	if msg := validateImageReference(simpleapp.Spec.Image); msg != "" {
+		return apierrors.NewInvalid(appsv1.GroupVersion.WithKind("SimpleApp").GroupKind(), simpleapp.Name,
+			field.ErrorList{field.Invalid(field.NewPath("spec", "image"), simpleapp.Spec.Image, msg)})
+	}
+	if canary := simpleapp.Spec.Canary; canary != nil {
+		if msg := validateImageReference(canary.Image); msg != "" {
+			return apierrors.NewInvalid(appsv1.GroupVersion.WithKind("SimpleApp").GroupKind(), simpleapp.Name,
+				field.ErrorList{field.Invalid(field.NewPath("spec", "canary", "image"), canary.Image, msg)})
+		}
+	}
+	return nil
+}
+
+// validateImagePolicies enforces the image policies configured for the SimpleApp's Namespace. old is
+// nil on creation. The image references must have been validated, as policies can't be checked
+// against one that doesn't parse.
+func (v *SimpleAppCustomValidator) validateImagePolicies(ctx context.Context, old, simpleapp *appsv1.SimpleApp) error {
+	imagePath := field.NewPath("spec", "image")
+	canaryPath := field.NewPath("spec", "canary", "image")
+	policies, err := v.imagePolicies(ctx, simpleapp.Namespace)
+	if err != nil {
+		return err
+	}
+
+	var allErrs field.ErrorList
+	name, tag, digest := splitImage(simpleapp.Spec.Image)
+	for _, imagePolicy := range policies {
+		switch imagePolicy {
+		case appsv1.ImagePolicyDigestRequired:
+			if digest == "" {
+				allErrs = append(allErrs, field.Invalid(imagePath, simpleapp.Spec.Image,
+					fmt.Sprintf("namespace %s requires images pinned by digest", simpleapp.Namespace)))
+			}
+			if canary := simpleapp.Spec.Canary; canary != nil {
+				if _, _, canaryDigest := splitImage(canary.Image); canaryDigest == "" {
+					allErrs = append(allErrs, field.Invalid(canaryPath, canary.Image,
+						fmt.Sprintf("namespace %s requires images pinned by digest", simpleapp.Namespace)))
+				}
+			}
+		case appsv1.ImagePolicyImmutableTag:
+			if old == nil {
+				continue
+			}
+			oldName, oldTag, oldDigest := splitImage(old.Spec.Image)
+			if oldDigest != "" && oldDigest == digest && oldName == name && oldTag != tag {
+				allErrs = append(allErrs, field.Forbidden(imagePath,
+					fmt.Sprintf("the tag of an image pinned by digest cannot change on its own; update the digest instead (namespace %s)", simpleapp.Namespace)))
+			}
+		default:
+			simpleapplog.Info("Ignoring unknown image policy", "namespace", simpleapp.Namespace, "policy", imagePolicy)
+		}
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(appsv1.GroupVersion.WithKind("SimpleApp").GroupKind(), simpleapp.Name, allErrs)
+}
@@ -0,0 +1,518 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appsv1 "github.com/gxanlvxgx/simple-app-operator/api/v1"
+)
+
+// staticPolicies is a policy.Source serving fixed data per namespace.
+type staticPolicies map[string]map[string]string
+
+func (s staticPolicies) Policy(_ context.Context, namespace string) (map[string]string, error) {
+	return s[namespace], nil
+}
+
+var _ = Describe("SimpleApp Webhook", func() {
+	var (
+		obj       *appsv1.SimpleApp
+		defaulter SimpleAppCustomDefaulter
+		ctx       = context.Background()
+	)
+
+	BeforeEach(func() {
+		obj = &appsv1.SimpleApp{
+			Spec: appsv1.SimpleAppSpec{
+				Image:         "nginx:1.25",
+				Replicas:      ptr.To[int32](2),
+				ContainerPort: 8080,
+				ServicePort:   80,
+			},
+		}
+		defaulter = SimpleAppCustomDefaulter{}
+	})
+
+	Context("When creating SimpleApp under Defaulting Webhook", func() {
+		It("Should default the ServicePort to 80 when omitted", func() {
+			obj.Spec.ServicePort = 0
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.ServicePort).To(Equal(int32(80)))
+		})
+
+		It("Should default the ServicePort to the ContainerPort when following it", func() {
+			defaulter.ServicePortFollowsContainerPort = true
+			obj.Spec.ServicePort = 0
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.ServicePort).To(Equal(int32(8080)))
+		})
+
+		It("Should keep an explicit ServicePort", func() {
+			for _, follows := range []bool{false, true} {
+				defaulter.ServicePortFollowsContainerPort = follows
+				obj.Spec.ServicePort = 8443
+				Expect(defaulter.Default(ctx, obj)).To(Succeed())
+				Expect(obj.Spec.ServicePort).To(Equal(int32(8443)))
+			}
+		})
+
+		It("Should default Replicas to 1 when omitted", func() {
+			obj.Spec.Replicas = nil
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.Replicas).To(HaveValue(Equal(int32(1))))
+		})
+
+		It("Should keep explicit Replicas", func() {
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.Replicas).To(HaveValue(Equal(int32(2))))
+		})
+
+		It("Should keep zero Replicas of an app scaled to zero", func() {
+			obj.Spec.Replicas = ptr.To[int32](0)
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.Replicas).To(HaveValue(BeZero()))
+		})
+
+		DescribeTable("Should normalize the image reference",
+			func(image, expected string) {
+				obj.Spec.Image = image
+				Expect(defaulter.Default(ctx, obj)).To(Succeed())
+				Expect(obj.Spec.Image).To(Equal(expected))
+			},
+			Entry("adds the implicit latest tag", "nginx", "nginx:latest"),
+			Entry("trims whitespace", "  nginx:1.25 ", "nginx:1.25"),
+			Entry("lowercases the repository", "Registry.Example.com/Team/App:v1", "registry.example.com/team/app:v1"),
+			Entry("keeps the tag case", "nginx:Stable-RC1", "nginx:Stable-RC1"),
+			Entry("does not mistake a registry port for a tag", "localhost:5000/app", "localhost:5000/app:latest"),
+			Entry("keeps digests untouched", "nginx@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+				"nginx@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"),
+			Entry("keeps tag and digest together", "nginx:1.25@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+				"nginx:1.25@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"),
+		)
+	})
+
+	Context("When defaulting security contexts", func() {
+		defaulterFor := func(enforce string) *SimpleAppCustomDefaulter {
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:   "prod",
+				Labels: map[string]string{"pod-security.kubernetes.io/enforce": enforce},
+			}}
+			return &SimpleAppCustomDefaulter{Reader: fake.NewClientBuilder().WithObjects(ns).Build()}
+		}
+
+		BeforeEach(func() {
+			obj.Namespace = "prod"
+		})
+
+		It("Should fill in restricted-compatible contexts in restricted namespaces", func() {
+			Expect(defaulterFor("restricted").Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.PodSecurityContext).NotTo(BeNil())
+			Expect(obj.Spec.PodSecurityContext.RunAsNonRoot).To(HaveValue(BeTrue()))
+			Expect(obj.Spec.PodSecurityContext.SeccompProfile.Type).To(Equal(corev1.SeccompProfileTypeRuntimeDefault))
+			Expect(obj.Spec.SecurityContext).NotTo(BeNil())
+			Expect(obj.Spec.SecurityContext.AllowPrivilegeEscalation).To(HaveValue(BeFalse()))
+			Expect(obj.Spec.SecurityContext.Capabilities.Drop).To(ConsistOf(corev1.Capability("ALL")))
+		})
+
+		It("Should keep explicit contexts", func() {
+			obj.Spec.SecurityContext = &corev1.SecurityContext{ReadOnlyRootFilesystem: ptr.To(true)}
+			Expect(defaulterFor("restricted").Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.SecurityContext.ReadOnlyRootFilesystem).To(HaveValue(BeTrue()))
+			Expect(obj.Spec.SecurityContext.AllowPrivilegeEscalation).To(BeNil())
+		})
+
+		It("Should leave the contexts empty in other namespaces", func() {
+			Expect(defaulterFor("baseline").Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.PodSecurityContext).To(BeNil())
+			Expect(obj.Spec.SecurityContext).To(BeNil())
+		})
+	})
+
+	Context("When validating SimpleApp image references", func() {
+		const digest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+		DescribeTable("Should accept well-formed references",
+			func(image string) {
+				obj.Spec.Image = image
+				validator := &SimpleAppCustomValidator{Reader: fake.NewClientBuilder().Build()}
+				_, err := validator.ValidateCreate(ctx, obj)
+				Expect(err).NotTo(HaveOccurred())
+			},
+			Entry("name and tag", "nginx:1.25"),
+			Entry("namespaced repository", "library/nginx:latest"),
+			Entry("registry with a port", "registry.example.com:5000/team/my_app:v1.2.3-rc.1"),
+			Entry("localhost registry", "localhost:5000/app:dev"),
+			Entry("digest only", "nginx@"+digest),
+			Entry("tag and digest", "ghcr.io/org/app:1.0@"+digest),
+		)
+
+		DescribeTable("Should reject malformed references",
+			func(image string) {
+				obj.Spec.Image = image
+				validator := &SimpleAppCustomValidator{Reader: fake.NewClientBuilder().Build()}
+				_, err := validator.ValidateCreate(ctx, obj)
+				Expect(err).To(MatchError(ContainSubstring("must be a valid image reference")))
+			},
+			Entry("double colon", "nginx::latest"),
+			Entry("empty tag", "nginx:"),
+			Entry("uppercase repository", "Nginx:1.25"),
+			Entry("whitespace", "nginx 1.25"),
+			Entry("trailing slash", "registry.example.com/"),
+			Entry("tag starting with a dot", "nginx:.1"),
+			Entry("short digest", "nginx@sha256:abc"),
+			Entry("empty", ""),
+		)
+
+		It("Should reject a malformed canary image", func() {
+			obj.Spec.Canary = &appsv1.CanarySpec{Image: "nginx::1.27", Weight: 10}
+			validator := &SimpleAppCustomValidator{Reader: fake.NewClientBuilder().Build()}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(And(ContainSubstring("spec.canary.image"), ContainSubstring("must be a valid image reference"))))
+		})
+
+		It("Should reject repository names longer than 255 characters", func() {
+			obj.Spec.Image = strings.Repeat("a", 256) + ":1.0"
+			validator := &SimpleAppCustomValidator{Reader: fake.NewClientBuilder().Build()}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("must not be longer than 255 characters")))
+		})
+	})
+
+	Context("When validating SimpleApp image policies", func() {
+		const (
+			digestA = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+			digestB = "sha256:fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210"
+		)
+
+		validatorFor := func(policy string) *SimpleAppCustomValidator {
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:        "prod",
+				Annotations: map[string]string{appsv1.ImagePolicyAnnotation: policy},
+			}}
+			return &SimpleAppCustomValidator{Reader: fake.NewClientBuilder().WithObjects(ns).Build()}
+		}
+
+		BeforeEach(func() {
+			obj.Namespace = "prod"
+		})
+
+		It("Should allow anything when the namespace has no policy", func() {
+			validator := validatorFor("")
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should require a digest when digest-required is set", func() {
+			validator := validatorFor(appsv1.ImagePolicyDigestRequired)
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("requires images pinned by digest")))
+
+			obj.Spec.Image = "nginx:1.25@" + digestA
+			_, err = validator.ValidateCreate(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("running a canary of an unpinned image")
+			obj.Spec.Canary = &appsv1.CanarySpec{Image: "nginx:1.27", Weight: 10}
+			_, err = validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(And(ContainSubstring("spec.canary.image"), ContainSubstring("requires images pinned by digest"))))
+		})
+
+		It("Should reject a tag-only change of a pinned image when immutable-tag is set", func() {
+			validator := validatorFor(appsv1.ImagePolicyDigestRequired + "," + appsv1.ImagePolicyImmutableTag)
+			old := obj.DeepCopy()
+			old.Spec.Image = "nginx:1.25@" + digestA
+
+			obj.Spec.Image = "nginx:1.26@" + digestA
+			_, err := validator.ValidateUpdate(ctx, old, obj)
+			Expect(err).To(MatchError(ContainSubstring("cannot change on its own")))
+
+			By("updating the digest along with the tag")
+			obj.Spec.Image = "nginx:1.26@" + digestB
+			_, err = validator.ValidateUpdate(ctx, old, obj)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should allow tag changes of unpinned images under immutable-tag", func() {
+			validator := validatorFor(appsv1.ImagePolicyImmutableTag)
+			old := obj.DeepCopy()
+			obj.Spec.Image = "nginx:1.26"
+			_, err := validator.ValidateUpdate(ctx, old, obj)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should combine the policies of the namespace policy ConfigMap with the annotation", func() {
+			validator := validatorFor(appsv1.ImagePolicyDigestRequired)
+			validator.Policies = staticPolicies{"prod": {
+				appsv1.PolicyImagePolicyKey: appsv1.ImagePolicyDigestRequired + "," + appsv1.ImagePolicyImmutableTag,
+			}}
+
+			_, err := validator.ValidateCreate(ctx, obj)
+			var statusErr *apierrors.StatusError
+			Expect(errors.As(err, &statusErr)).To(BeTrue())
+			Expect(statusErr.ErrStatus.Details.Causes).To(HaveLen(1), "a policy set twice is enforced once")
+
+			By("enforcing a policy only set in the ConfigMap")
+			old := obj.DeepCopy()
+			old.Spec.Image = "nginx:1.25@" + digestA
+			obj.Spec.Image = "nginx:1.26@" + digestA
+			_, err = validator.ValidateUpdate(ctx, old, obj)
+			Expect(err).To(MatchError(ContainSubstring("cannot change on its own")))
+		})
+	})
+
+	Context("When validating SimpleApp replica sources", func() {
+		It("Should allow following the node count", func() {
+			obj.Spec.ReplicasFromNodeCount = true
+			validator := &SimpleAppCustomValidator{Reader: fake.NewClientBuilder().Build()}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should reject following the node count together with autoscaling", func() {
+			obj.Spec.ReplicasFromNodeCount = true
+			obj.Spec.Autoscaling = &appsv1.AutoscalingSpec{MaxReplicas: 5}
+			validator := &SimpleAppCustomValidator{Reader: fake.NewClientBuilder().Build()}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("cannot be combined with spec.autoscaling")))
+
+			_, err = validator.ValidateUpdate(ctx, obj.DeepCopy(), obj)
+			Expect(err).To(MatchError(ContainSubstring("cannot be combined with spec.autoscaling")))
+		})
+	})
+
+	Context("When validating SimpleApp disruption budgets", func() {
+		DescribeTable("Should warn only when the budget blocks every eviction",
+			func(replicas int32, minAvailable, maxUnavailable *intstr.IntOrString, warns bool) {
+				obj.Spec.Replicas = ptr.To(replicas)
+				obj.Spec.PodDisruptionBudget = &appsv1.PodDisruptionBudgetSpec{
+					MinAvailable:   minAvailable,
+					MaxUnavailable: maxUnavailable,
+				}
+				validator := &SimpleAppCustomValidator{Reader: fake.NewClientBuilder().Build()}
+				warnings, err := validator.ValidateCreate(ctx, obj)
+				Expect(err).NotTo(HaveOccurred())
+				if warns {
+					Expect(warnings).To(ConsistOf(ContainSubstring("node drains will hang")))
+				} else {
+					Expect(warnings).To(BeEmpty())
+				}
+			},
+			Entry("minAvailable equal to replicas", int32(2), ptr.To(intstr.FromInt32(2)), nil, true),
+			Entry("minAvailable below replicas", int32(3), ptr.To(intstr.FromInt32(2)), nil, false),
+			Entry("minAvailable 100%", int32(3), ptr.To(intstr.FromString("100%")), nil, true),
+			Entry("minAvailable 50% of one replica rounds up", int32(1), ptr.To(intstr.FromString("50%")), nil, true),
+			Entry("maxUnavailable zero", int32(3), nil, ptr.To(intstr.FromInt32(0)), true),
+			Entry("maxUnavailable one", int32(3), nil, ptr.To(intstr.FromInt32(1)), false),
+			Entry("no replicas to evict", int32(0), ptr.To(intstr.FromInt32(1)), nil, false),
+		)
+	})
+
+	Context("When validating SimpleApp storage updates", func() {
+		DescribeTable("Should only allow the claim to grow",
+			func(mutate func(*appsv1.StorageSpec), rejected string) {
+				obj.Spec.Storage = &appsv1.StorageSpec{
+					Size:             resource.MustParse("10Gi"),
+					StorageClassName: ptr.To("fast"),
+					MountPath:        "/data",
+				}
+				updated := obj.DeepCopy()
+				mutate(updated.Spec.Storage)
+				validator := &SimpleAppCustomValidator{Reader: fake.NewClientBuilder().Build()}
+				_, err := validator.ValidateUpdate(ctx, obj, updated)
+				if rejected == "" {
+					Expect(err).NotTo(HaveOccurred())
+				} else {
+					Expect(apierrors.IsInvalid(err)).To(BeTrue())
+					Expect(err).To(MatchError(ContainSubstring(rejected)))
+				}
+			},
+			Entry("growing the size", func(s *appsv1.StorageSpec) { s.Size = resource.MustParse("20Gi") }, ""),
+			Entry("shrinking the size", func(s *appsv1.StorageSpec) { s.Size = resource.MustParse("5Gi") }, "spec.storage.size"),
+			Entry("changing the storage class", func(s *appsv1.StorageSpec) { s.StorageClassName = ptr.To("slow") },
+				"spec.storage.storageClassName"),
+			Entry("changing the mount path", func(s *appsv1.StorageSpec) { s.MountPath = "/var/data" }, ""),
+		)
+	})
+
+	Context("When validating SimpleApp selector labels", func() {
+		It("Should reject invalid label keys and values", func() {
+			obj.Spec.SelectorLabels = map[string]string{"app.kubernetes.io/name": "not a value"}
+			validator := &SimpleAppCustomValidator{Reader: fake.NewClientBuilder().Build()}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err).To(MatchError(ContainSubstring("spec.selectorLabels")))
+		})
+
+		It("Should reject invalid Deployment labels", func() {
+			obj.Spec.DeploymentLabels = map[string]string{"not a key": "value"}
+			validator := &SimpleAppCustomValidator{Reader: fake.NewClientBuilder().Build()}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err).To(MatchError(ContainSubstring("spec.deploymentLabels")))
+		})
+
+		DescribeTable("Should reject changing them on update",
+			func(before, after map[string]string, rejected bool) {
+				obj.Spec.SelectorLabels = before
+				updated := obj.DeepCopy()
+				updated.Spec.SelectorLabels = after
+				validator := &SimpleAppCustomValidator{Reader: fake.NewClientBuilder().Build()}
+				_, err := validator.ValidateUpdate(ctx, obj, updated)
+				if rejected {
+					Expect(apierrors.IsInvalid(err)).To(BeTrue())
+					Expect(err).To(MatchError(ContainSubstring("spec.selectorLabels")))
+				} else {
+					Expect(err).NotTo(HaveOccurred())
+				}
+			},
+			Entry("unchanged", map[string]string{"tier": "web"}, map[string]string{"tier": "web"}, false),
+			Entry("added", nil, map[string]string{"tier": "web"}, true),
+			Entry("removed", map[string]string{"tier": "web"}, nil, true),
+			Entry("modified", map[string]string{"tier": "web"}, map[string]string{"tier": "api"}, true),
+		)
+	})
+
+	Context("When validating the SimpleApp priority class", func() {
+		DescribeTable("Should only accept DNS-1123 subdomains",
+			func(name string, valid bool) {
+				obj.Spec.PriorityClassName = name
+				validator := &SimpleAppCustomValidator{Reader: fake.NewClientBuilder().Build()}
+				_, err := validator.ValidateCreate(ctx, obj)
+				if valid {
+					Expect(err).NotTo(HaveOccurred())
+				} else {
+					Expect(apierrors.IsInvalid(err)).To(BeTrue())
+					Expect(err).To(MatchError(ContainSubstring("spec.priorityClassName")))
+				}
+			},
+			Entry("unset", "", true),
+			Entry("a plain name", "latency-critical", true),
+			Entry("a dotted name", "prod.latency-critical", true),
+			Entry("upper case", "Critical", false),
+			Entry("an underscore", "latency_critical", false),
+		)
+	})
+
+	Context("When validating the SimpleApp node architecture", func() {
+		DescribeTable("Should only accept known architectures matching the node selector",
+			func(arch string, nodeSelector map[string]string, invalidField string) {
+				obj.Spec.NodeArch = arch
+				obj.Spec.NodeSelector = nodeSelector
+				validator := &SimpleAppCustomValidator{Reader: fake.NewClientBuilder().Build()}
+				_, err := validator.ValidateCreate(ctx, obj)
+				if invalidField == "" {
+					Expect(err).NotTo(HaveOccurred())
+				} else {
+					Expect(apierrors.IsInvalid(err)).To(BeTrue())
+					Expect(err).To(MatchError(ContainSubstring(invalidField)))
+				}
+			},
+			Entry("unset", "", nil, ""),
+			Entry("arm64", "arm64", map[string]string{"disktype": "ssd"}, ""),
+			Entry("an unknown architecture", "x86", nil, "spec.nodeArch"),
+			Entry("a matching node selector", "amd64", map[string]string{"kubernetes.io/arch": "amd64"}, ""),
+			Entry("a contradicting node selector", "amd64", map[string]string{"kubernetes.io/arch": "arm64"},
+				"spec.nodeSelector[kubernetes.io/arch]"),
+		)
+	})
+
+	Context("When validating SimpleApp ports", func() {
+		DescribeTable("Should reject invalid port combinations, naming the field",
+			func(mutate func(*appsv1.SimpleAppSpec), rejected string) {
+				mutate(&obj.Spec)
+				validator := &SimpleAppCustomValidator{Reader: fake.NewClientBuilder().Build()}
+				_, err := validator.ValidateCreate(ctx, obj)
+				if rejected == "" {
+					Expect(err).NotTo(HaveOccurred())
+				} else {
+					Expect(apierrors.IsInvalid(err)).To(BeTrue())
+					Expect(err).To(MatchError(ContainSubstring(rejected)))
+				}
+			},
+			Entry("distinct ports", func(*appsv1.SimpleAppSpec) {}, ""),
+			Entry("containerPort zero", func(s *appsv1.SimpleAppSpec) { s.ContainerPort = 0 }, "spec.containerPort"),
+			Entry("containerPort out of range", func(s *appsv1.SimpleAppSpec) { s.ContainerPort = 70000 }, "spec.containerPort"),
+			Entry("servicePort out of range", func(s *appsv1.SimpleAppSpec) { s.ServicePort = -1 }, "spec.servicePort"),
+			Entry("metrics on the Service port", func(s *appsv1.SimpleAppSpec) {
+				s.Metrics = &appsv1.MetricsSpec{Port: 80}
+			}, "spec.metrics.port"),
+			Entry("metrics on the container port", func(s *appsv1.SimpleAppSpec) {
+				s.Metrics = &appsv1.MetricsSpec{Port: 8080}
+			}, ""),
+			Entry("distinct admin port", func(s *appsv1.SimpleAppSpec) { s.AdminPort = ptr.To(int32(8081)) }, ""),
+			Entry("admin port on the container port", func(s *appsv1.SimpleAppSpec) {
+				s.AdminPort = ptr.To(int32(8080))
+			}, "spec.adminPort"),
+			Entry("admin port on the Service port", func(s *appsv1.SimpleAppSpec) {
+				s.AdminPort = ptr.To(int32(80))
+			}, "spec.adminPort"),
+		)
+
+		It("Should warn when a headless Service remaps the container port", func() {
+			obj.Spec.Headless = true
+			validator := &SimpleAppCustomValidator{Reader: fake.NewClientBuilder().Build()}
+			warnings, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(ConsistOf(ContainSubstring("clients of a headless Service connect to the pods")))
+
+			obj.Spec.ServicePort = obj.Spec.ContainerPort
+			warnings, err = validator.ValidateCreate(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+		})
+
+		It("Should validate an omitted ServicePort as its default", func() {
+			obj.Spec.ServicePort = 0
+			obj.Spec.Headless = true
+			validator := &SimpleAppCustomValidator{Reader: fake.NewClientBuilder().Build()}
+			warnings, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(ConsistOf(ContainSubstring("set servicePort to 8080")))
+
+			validator.ServicePortFollowsContainerPort = true
+			warnings, err = validator.ValidateCreate(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+		})
+
+		It("Should only publish not-ready addresses of headless Services", func() {
+			obj.Spec.PublishNotReadyAddresses = ptr.To(true)
+			validator := &SimpleAppCustomValidator{Reader: fake.NewClientBuilder().Build()}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err).To(MatchError(ContainSubstring("spec.publishNotReadyAddresses")))
+
+			obj.Spec.Headless = true
+			obj.Spec.ServicePort = obj.Spec.ContainerPort
+			_, err = validator.ValidateCreate(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+})